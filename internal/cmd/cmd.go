@@ -6,6 +6,7 @@ import (
 	"github.com/furiosa-ai/furiosa-metrics-exporter/internal/collector"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 
 	"github.com/furiosa-ai/furiosa-metrics-exporter/internal/config"
@@ -46,14 +47,180 @@ func NewCommand() *cobra.Command {
 				cfg.SetKubeResourcesLabel(kubeResourcesLabel)
 			}
 
+			if kubeletSocket, err := cmd.Flags().GetString("kubelet-socket"); err != nil {
+				return err
+			} else {
+				cfg.SetKubeletSocket(kubeletSocket)
+			}
+
+			if kubeCacheTTL, err := cmd.Flags().GetInt("kube-cache-ttl"); err != nil {
+				return err
+			} else {
+				cfg.SetKubeCacheTTL(kubeCacheTTL)
+			}
+
+			if cgroupFallback, err := cmd.Flags().GetBool("cgroup-fallback"); err != nil {
+				return err
+			} else {
+				cfg.SetCgroupFallback(cgroupFallback)
+			}
+
+			if checkpointFallback, err := cmd.Flags().GetBool("checkpoint-fallback"); err != nil {
+				return err
+			} else {
+				cfg.SetCheckpointFallback(checkpointFallback)
+			}
+
+			if kubeletCheckpoint, err := cmd.Flags().GetString("kubelet-checkpoint"); err != nil {
+				return err
+			} else {
+				cfg.SetKubeletCheckpoint(kubeletCheckpoint)
+			}
+
+			if sysfsRoot, err := cmd.Flags().GetString("sysfs-root"); err != nil {
+				return err
+			} else {
+				cfg.SetSysfsRoot(sysfsRoot)
+			}
+
+			if infoMetricLabels, err := cmd.Flags().GetBool("info-metric-labels"); err != nil {
+				return err
+			} else {
+				cfg.SetInfoMetricLabels(infoMetricLabels)
+			}
+
+			if processLabel, err := cmd.Flags().GetBool("process-label"); err != nil {
+				return err
+			} else {
+				cfg.SetProcessLabel(processLabel)
+			}
+
+			if procRoot, err := cmd.Flags().GetString("proc-root"); err != nil {
+				return err
+			} else {
+				cfg.SetProcRoot(procRoot)
+			}
+
+			if podInformer, err := cmd.Flags().GetBool("pod-informer"); err != nil {
+				return err
+			} else {
+				cfg.SetPodInformer(podInformer)
+			}
+
+			if kubeConfig, err := cmd.Flags().GetString("kubeconfig"); err != nil {
+				return err
+			} else {
+				cfg.SetKubeConfig(kubeConfig)
+			}
+
+			if podLabelsAllowlist, err := cmd.Flags().GetStringSlice("pod-labels-allowlist"); err != nil {
+				return err
+			} else {
+				cfg.SetPodLabelsAllowlist(podLabelsAllowlist)
+			}
+
+			if podAnnotationsAllowlist, err := cmd.Flags().GetStringSlice("pod-annotations-allowlist"); err != nil {
+				return err
+			} else {
+				cfg.SetPodAnnotationsAllowlist(podAnnotationsAllowlist)
+			}
+
+			if podOwnerLabels, err := cmd.Flags().GetBool("pod-owner-labels"); err != nil {
+				return err
+			} else {
+				cfg.SetPodOwnerLabels(podOwnerLabels)
+			}
+
+			if attributionMode, err := cmd.Flags().GetString("attribution-mode"); err != nil {
+				return err
+			} else {
+				cfg.SetAttributionMode(attributionMode)
+			}
+
+			if livenessFlappingThreshold, err := cmd.Flags().GetInt("liveness-flapping-threshold"); err != nil {
+				return err
+			} else {
+				cfg.SetLivenessFlappingThreshold(livenessFlappingThreshold)
+			}
+
+			if livenessFlappingWindow, err := cmd.Flags().GetInt("liveness-flapping-window"); err != nil {
+				return err
+			} else {
+				cfg.SetLivenessFlappingWindow(livenessFlappingWindow)
+			}
+
+			if livenessFastInterval, err := cmd.Flags().GetInt("liveness-fast-interval"); err != nil {
+				return err
+			} else {
+				cfg.SetLivenessFastInterval(livenessFastInterval)
+			}
+
+			if idleUtilizationThreshold, err := cmd.Flags().GetFloat64("idle-utilization-threshold"); err != nil {
+				return err
+			} else {
+				cfg.SetIdleUtilizationThreshold(idleUtilizationThreshold)
+			}
+
+			if podAggregateMetrics, err := cmd.Flags().GetBool("pod-aggregate-metrics"); err != nil {
+				return err
+			} else {
+				cfg.SetPodAggregateMetrics(podAggregateMetrics)
+			}
+
+			if thermalLimits, err := getFloatMapFlag(cmd, "thermal-limits"); err != nil {
+				return err
+			} else {
+				cfg.SetThermalLimits(thermalLimits)
+			}
+
+			if powerLimits, err := getFloatMapFlag(cmd, "power-limits"); err != nil {
+				return err
+			} else {
+				cfg.SetPowerLimits(powerLimits)
+			}
+
+			if nominalCoreFrequencies, err := getFloatMapFlag(cmd, "nominal-core-frequencies"); err != nil {
+				return err
+			} else {
+				cfg.SetNominalCoreFrequencies(nominalCoreFrequencies)
+			}
+
+			if err := cfg.Validate(); err != nil {
+				return err
+			}
+
 			return Run(cmd.Context(), cfg)
 		},
 	}
 
+	defaults := config.NewDefaultConfig()
 	cmd.Flags().Int("port", 0, "[Required] Port number used for metrics server")
 	cmd.Flags().Int("interval", 0, "[Required] Collection interval value in second")
 	cmd.Flags().String("node-name", "", "Node name of the current execution environment")
 	cmd.Flags().Bool("kube-resources-label", false, "Enable kubernetes resources label injection")
+	cmd.Flags().String("kubelet-socket", defaults.KubeletSocket, "Path of the kubelet pod resources socket")
+	cmd.Flags().Int("kube-cache-ttl", defaults.KubeCacheTTL, "Duration (seconds) to keep the last pod information while the kubelet pod resources API is unreachable")
+	cmd.Flags().Bool("cgroup-fallback", false, "Attribute NPU devices to pod uid and container id from the cgroup of the processes holding them, when the kubelet pod resources API is unavailable")
+	cmd.Flags().Bool("checkpoint-fallback", false, "Attribute NPU devices to pod uid and container name from the kubelet device manager checkpoint, when the kubelet pod resources API is unavailable")
+	cmd.Flags().String("kubelet-checkpoint", defaults.KubeletCheckpoint, "Path of the kubelet device manager checkpoint")
+	cmd.Flags().String("sysfs-root", defaults.SysfsRoot, "Root directory of sysfs to read device error counters from")
+	cmd.Flags().Bool("info-metric-labels", false, "Move version, pci_bus_id and hostname labels to the furiosa_npu_info metric")
+	cmd.Flags().Bool("process-label", false, "Label metrics with the pid, comm and user of the processes holding the NPU device files, instead of kubernetes resources")
+	cmd.Flags().String("proc-root", defaults.ProcRoot, "Root directory of procfs to scan for processes holding the NPU device files")
+	cmd.Flags().Bool("pod-informer", false, "Watch the pods of the node through the API server to enrich the kubernetes resources labels, requires the node name")
+	cmd.Flags().String("kubeconfig", "", "Path of the kubeconfig file used by the pod informer, in-cluster config if empty")
+	cmd.Flags().StringSlice("pod-labels-allowlist", nil, "Pod labels added as label_<name> labels by the pod informer, e.g. team,app.kubernetes.io/name")
+	cmd.Flags().StringSlice("pod-annotations-allowlist", nil, "Pod annotations added as annotation_<name> labels by the pod informer")
+	cmd.Flags().Bool("pod-owner-labels", false, "Add the owner_kind and owner_name labels of the top level workload owning the pod, such as a Deployment, with the pod informer")
+	cmd.Flags().String("attribution-mode", defaults.AttributionMode, "How the device metrics are attributed to the pods: duplicate, replace, split or info")
+	cmd.Flags().Int("liveness-flapping-threshold", defaults.LivenessFlappingThreshold, "Number of liveness changes within the flapping window above which a device is flapping")
+	cmd.Flags().Int("liveness-flapping-window", defaults.LivenessFlappingWindow, "Window (seconds) in which the liveness changes are counted for flapping detection")
+	cmd.Flags().Int("liveness-fast-interval", defaults.LivenessFastInterval, "Liveness sampling interval (seconds) while a device is recently unhealthy, 0 to disable")
+	cmd.Flags().Float64("idle-utilization-threshold", defaults.IdleUtilizationThreshold, "Core utilization (percent) under which the cores allocated to a pod are considered idle")
+	cmd.Flags().Bool("pod-aggregate-metrics", false, "Export the power, energy and utilization aggregated by pod and namespace, split by the fraction of the allocated cores, with the kubernetes resources label")
+	cmd.Flags().StringToString("thermal-limits", nil, "Thermal limits (Celsius) by arch overriding the defaults, e.g. rngd=85,warboy=80")
+	cmd.Flags().StringToString("power-limits", nil, "Power limits (W) by arch overriding the defaults, e.g. rngd=180,warboy=40")
+	cmd.Flags().StringToString("nominal-core-frequencies", nil, "Nominal core frequencies (MHz) by arch overriding the defaults, below which a device close to its thermal limit is throttled, e.g. rngd=1000")
 
 	if err := cmd.MarkFlagRequired("port"); err != nil {
 		panic(err)
@@ -66,6 +233,24 @@ func NewCommand() *cobra.Command {
 	return cmd
 }
 
+func getFloatMapFlag(cmd *cobra.Command, name string) (map[string]float64, error) {
+	raw, err := cmd.Flags().GetStringToString(name)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]float64, len(raw))
+	for key, value := range raw {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value '%s' of '%s' for --%s: %w", value, key, name, err)
+		}
+		values[key] = parsed
+	}
+
+	return values, nil
+}
+
 func Run(ctx context.Context, cfg *config.Config) error {
 	ctx, cancelFunc := context.WithCancel(ctx)
 	defer cancelFunc()
@@ -100,7 +285,7 @@ func Run(ctx context.Context, cfg *config.Config) error {
 	}
 
 	// Prepare Metric Factory
-	metricFactory := collector.NewMetricFactory(cfg.NodeName, driverInfo.String())
+	metricFactory := collector.NewMetricFactory(cfg.NodeName, driverInfo.String(), cfg.InfoMetricLabels)
 
 	// Create Exporter
 	errChan := make(chan error, 1)
@@ -0,0 +1,105 @@
+package collector
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+
+	"github.com/furiosa-ai/furiosa-smi-go/pkg/smi"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	allocationState = "allocationState"
+)
+
+// allocationCollector exports whether the kubelet considers each core allocated, allocatable or unavailable.
+type allocationCollector struct {
+	devices       []smi.Device
+	metricFactory MetricFactory
+	gaugeVec      *prometheus.GaugeVec
+	kubeResMapper AllocationStateMapper
+}
+
+var _ Collector = (*allocationCollector)(nil)
+
+func NewAllocationCollector(devices []smi.Device, metricFactory MetricFactory, kubeResMapper AllocationStateMapper) Collector {
+	return &allocationCollector{
+		devices:       devices,
+		metricFactory: metricFactory,
+		kubeResMapper: kubeResMapper,
+	}
+}
+
+func (t *allocationCollector) Register() {
+	opts := prometheus.GaugeOpts{
+		Name: "furiosa_npu_allocation_state",
+		Help: "The allocation state (allocated, allocatable, unavailable) of NPU core according to the kubelet. The value is always 1",
+	}
+
+	t.gaugeVec = prometheus.NewGaugeVec(opts, append(defaultMetricLabels(t.kubeResMapper.MetricLabels()...), state))
+
+	prometheus.MustRegister(NewLabelFilterCollector(
+		t.gaugeVec,
+		prometheus.Opts(opts),
+		prometheus.GaugeValue,
+	))
+}
+
+func (t *allocationCollector) Collect() error {
+	metricContainer := make(MetricContainer, 0, len(t.devices))
+
+	errs := make([]error, 0)
+	for _, d := range t.devices {
+		metric, err := t.metricFactory.NewDeviceWiseMetric(d)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		info, err := getDeviceInfo(d)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		cores := slices.Clone(info.cores)
+		slices.Sort(cores)
+
+		for _, c := range cores {
+			value, found := t.kubeResMapper.allocationState(info.uuid, int(c))
+			if !found {
+				continue
+			}
+
+			duplicated := deepCopyMetric(metric)
+			duplicated[core] = strconv.Itoa(int(c))
+			duplicated[allocationState] = value
+			metricContainer = append(metricContainer, duplicated)
+		}
+	}
+
+	if err := t.postProcess(metricContainer); err != nil {
+		errs = append(errs, err)
+	}
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	return nil
+}
+
+func (t *allocationCollector) postProcess(metrics MetricContainer) error {
+	labelNames := defaultMetricLabels(t.kubeResMapper.MetricLabels()...)
+	transformed := t.kubeResMapper.TransformDeviceMetrics(metrics, true)
+	t.gaugeVec.Reset()
+
+	for _, metric := range transformed {
+		if value, ok := metric[allocationState]; ok {
+			t.gaugeVec.With(metricLabels(labelNames, metric, prometheus.Labels{state: value.(string)})).Set(1)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,276 @@
+package collector
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/furiosa-ai/furiosa-smi-go/pkg/smi"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	allocatedIdleSeconds = "allocatedIdleSeconds"
+	allocatedIdleSince   = "allocatedIdleSince"
+)
+
+// allocationIdleCollector exports how long the cores allocated to each pod stay under the utilization threshold, so
+// that the pods holding NPUs without using them can be reclaimed.
+type allocationIdleCollector struct {
+	devices               []smi.Device
+	metricFactory         MetricFactory
+	kubeResMapper         AllocationStateMapper
+	utilizationThreshold  float64
+	tracker               *idleTracker
+	idleSecondsCounterVec *prometheus.CounterVec
+	idleSinceGaugeVec     *prometheus.GaugeVec
+}
+
+var _ Collector = (*allocationIdleCollector)(nil)
+
+// NewAllocationIdleCollector creates a collector considering a pod idle while the utilization of all its allocated
+// cores is under utilizationThreshold (percent).
+func NewAllocationIdleCollector(devices []smi.Device, metricFactory MetricFactory, kubeResMapper AllocationStateMapper, utilizationThreshold float64) Collector {
+	return &allocationIdleCollector{
+		devices:              devices,
+		metricFactory:        metricFactory,
+		kubeResMapper:        kubeResMapper,
+		utilizationThreshold: utilizationThreshold,
+		tracker:              newIdleTracker(time.Now),
+	}
+}
+
+func (t *allocationIdleCollector) Register() {
+	idleSecondsOpts := prometheus.CounterOpts{
+		Name: "furiosa_npu_allocated_idle_seconds_total",
+		Help: "The time during which the cores of NPU device allocated to the pod stayed under the utilization threshold (seconds)",
+	}
+
+	t.idleSecondsCounterVec = prometheus.NewCounterVec(idleSecondsOpts, defaultMetricLabels(t.kubeResMapper.MetricLabels()...))
+
+	prometheus.MustRegister(NewLabelFilterCollector(
+		t.idleSecondsCounterVec,
+		prometheus.Opts(idleSecondsOpts),
+		prometheus.CounterValue,
+	))
+
+	idleSinceOpts := prometheus.GaugeOpts{
+		Name: "furiosa_npu_allocated_idle_since_timestamp_seconds",
+		Help: "The unix timestamp since which the cores of NPU device allocated to the pod are under the utilization threshold, exported only while idle",
+	}
+
+	t.idleSinceGaugeVec = prometheus.NewGaugeVec(idleSinceOpts, defaultMetricLabels(t.kubeResMapper.MetricLabels()...))
+
+	prometheus.MustRegister(NewLabelFilterCollector(
+		t.idleSinceGaugeVec,
+		prometheus.Opts(idleSinceOpts),
+		prometheus.GaugeValue,
+	))
+}
+
+func (t *allocationIdleCollector) Collect() error {
+	metricContainer := make(MetricContainer, 0, len(t.devices))
+
+	errs := make([]error, 0)
+	seen := make(map[string]bool)
+	for _, d := range t.devices {
+		metric, err := t.metricFactory.NewDeviceWiseMetric(d)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		podInfoSlice := t.kubeResMapper.allocatedPods(metric[uuid].(string))
+		if len(podInfoSlice) == 0 {
+			continue
+		}
+
+		coreUtilization, err := d.CoreUtilization()
+		if err != nil {
+			// the last idle state is exported until the utilization can be read again
+			for _, podInformation := range podInfoSlice {
+				key := idleKey(metric[uuid].(string), podInformation)
+				if state, found := t.tracker.last(key); found {
+					seen[key] = true
+					metricContainer = append(metricContainer, withIdleState(metric, podInformation, state))
+				}
+			}
+
+			errs = append(errs, err)
+			continue
+		}
+
+		utilizations := make(map[int]float64)
+		for _, pe := range coreUtilization.PeUtilization() {
+			utilizations[int(pe.Core())] = pe.PeUsagePercentage()
+		}
+
+		for _, podInformation := range podInfoSlice {
+			key := idleKey(metric[uuid].(string), podInformation)
+			idle, ok := isAllocationIdle(utilizations, podInformation.AllocatedPE, t.utilizationThreshold)
+			if !ok {
+				// the last idle state is exported until the utilization of the allocated cores is known again
+				if state, found := t.tracker.last(key); found {
+					seen[key] = true
+					metricContainer = append(metricContainer, withIdleState(metric, podInformation, state))
+				}
+				continue
+			}
+
+			seen[key] = true
+			state := t.tracker.observe(key, idle)
+			metricContainer = append(metricContainer, withIdleState(metric, podInformation, state))
+		}
+	}
+
+	// the pods that are gone are forgotten
+	t.tracker.prune(seen)
+
+	if err := t.postProcess(metricContainer); err != nil {
+		errs = append(errs, err)
+	}
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	return nil
+}
+
+// withIdleState returns the metric attributed to the pod with its idle state.
+func withIdleState(metric Metric, podInformation podInfo, state idleState) Metric {
+	attributed := withPodLabels(metric, podInformation, true)
+	attributed[allocatedIdleSeconds] = state.idleSeconds
+	if !state.idleSince.IsZero() {
+		attributed[allocatedIdleSince] = float64(state.idleSince.UnixNano()) / float64(time.Second)
+	}
+
+	return attributed
+}
+
+// postProcess exports the metrics, which are already attributed to the pods.
+func (t *allocationIdleCollector) postProcess(metrics MetricContainer) error {
+	labelNames := defaultMetricLabels(t.kubeResMapper.MetricLabels()...)
+	t.idleSecondsCounterVec.Reset()
+	t.idleSinceGaugeVec.Reset()
+
+	for _, metric := range metrics {
+		if value, ok := metric[allocatedIdleSeconds]; ok {
+			t.idleSecondsCounterVec.With(metricLabels(labelNames, metric, nil)).Add(value.(float64))
+		}
+
+		if value, ok := metric[allocatedIdleSince]; ok {
+			t.idleSinceGaugeVec.With(metricLabels(labelNames, metric, nil)).Set(value.(float64))
+		}
+	}
+
+	return nil
+}
+
+// isAllocationIdle returns whether the utilization of all the allocated cores is under the threshold. It returns
+// false if the utilization of none of the cores is known.
+func isAllocationIdle(utilizations map[int]float64, allocatedPE []int, threshold float64) (bool, bool) {
+	known := false
+	for _, coreIdx := range allocatedPE {
+		value, ok := utilizations[coreIdx]
+		if !ok {
+			continue
+		}
+
+		known = true
+		if value >= threshold {
+			return false, true
+		}
+	}
+
+	return known, known
+}
+
+// idleKey identifies the allocation of the cores of the device to the pod, by the pod uid or the container id if the
+// uid is unknown, so that the idle time is kept while the pod information is enriched. The names are used only if
+// neither is known, as the podresources API does not report them.
+func idleKey(uuidValue string, podInformation podInfo) string {
+	owner := podInformation.UID
+	if owner == "" {
+		owner = podInformation.ContainerID
+	}
+	if owner == "" {
+		owner = strings.Join([]string{podInformation.Namespace, podInformation.Name, podInformation.ContainerName}, "/")
+	}
+
+	return strings.Join([]string{uuidValue, podInformation.CoreLabel, owner}, "/")
+}
+
+type idleState struct {
+	idleSince    time.Time
+	idleSeconds  float64
+	lastObserved time.Time
+}
+
+// idleTracker accumulates the idle time of each allocation between the observations.
+type idleTracker struct {
+	sync.Mutex
+	now func() time.Time
+
+	// states maps the idle key of each allocation to its idle state.
+	states map[string]*idleState
+}
+
+func newIdleTracker(now func() time.Time) *idleTracker {
+	return &idleTracker{
+		now:    now,
+		states: make(map[string]*idleState),
+	}
+}
+
+// observe records whether the allocation is idle and returns a copy of its state. The time between two idle
+// observations is accumulated, so an allocation becomes idle at its first idle observation.
+func (i *idleTracker) observe(key string, idle bool) idleState {
+	i.Lock()
+	defer i.Unlock()
+
+	now := i.now()
+	state, found := i.states[key]
+	if !found {
+		state = &idleState{}
+		i.states[key] = state
+	}
+
+	if !idle {
+		state.idleSince = time.Time{}
+	} else if state.idleSince.IsZero() {
+		state.idleSince = now
+	} else {
+		state.idleSeconds += now.Sub(state.lastObserved).Seconds()
+	}
+
+	state.lastObserved = now
+
+	return *state
+}
+
+// last returns a copy of the last observed state of the allocation.
+func (i *idleTracker) last(key string) (idleState, bool) {
+	i.Lock()
+	defer i.Unlock()
+
+	state, found := i.states[key]
+	if !found {
+		return idleState{}, false
+	}
+
+	return *state, true
+}
+
+// prune forgets the allocations that are not in seen.
+func (i *idleTracker) prune(seen map[string]bool) {
+	i.Lock()
+	defer i.Unlock()
+
+	for key := range i.states {
+		if !seen[key] {
+			delete(i.states, key)
+		}
+	}
+}
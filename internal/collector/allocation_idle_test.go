@@ -0,0 +1,170 @@
+package collector
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/furiosa-ai/furiosa-smi-go/pkg/smi"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsAllocationIdle(t *testing.T) {
+	utilizations := map[int]float64{0: 0.5, 1: 0, 2: 30}
+
+	idle, ok := isAllocationIdle(utilizations, []int{0, 1}, 1)
+	assert.True(t, ok)
+	assert.True(t, idle)
+
+	idle, ok = isAllocationIdle(utilizations, []int{0, 1, 2}, 1)
+	assert.True(t, ok)
+	assert.False(t, idle, "a single busy core makes the allocation busy")
+
+	_, ok = isAllocationIdle(utilizations, []int{6, 7}, 1)
+	assert.False(t, ok, "the utilization of the allocated cores is unknown")
+}
+
+func TestIdleTracker_Observe(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	now := start
+	tracker := newIdleTracker(func() time.Time { return now })
+
+	state := tracker.observe("pod-a", true)
+	assert.Equal(t, start, state.idleSince)
+	assert.Equal(t, float64(0), state.idleSeconds)
+
+	now = start.Add(10 * time.Second)
+	state = tracker.observe("pod-a", true)
+	assert.Equal(t, start, state.idleSince)
+	assert.Equal(t, float64(10), state.idleSeconds)
+
+	// the idle time is kept while busy, and accumulated again from the next idle observation
+	now = start.Add(20 * time.Second)
+	state = tracker.observe("pod-a", false)
+	assert.True(t, state.idleSince.IsZero())
+	assert.Equal(t, float64(10), state.idleSeconds)
+
+	now = start.Add(30 * time.Second)
+	state = tracker.observe("pod-a", true)
+	assert.Equal(t, now, state.idleSince)
+	assert.Equal(t, float64(10), state.idleSeconds)
+
+	now = start.Add(45 * time.Second)
+	state = tracker.observe("pod-a", true)
+	assert.Equal(t, float64(25), state.idleSeconds)
+
+	tracker.prune(map[string]bool{})
+	state = tracker.observe("pod-a", true)
+	assert.Equal(t, float64(0), state.idleSeconds, "the pruned allocation starts over")
+}
+
+func TestIdleKey(t *testing.T) {
+	deviceUUID := "A76AAD68-6855-40B1-9E86-D080852D1C80"
+
+	// the key is kept while the pod attributed by the cgroup is named by the pod informer
+	cgroupPod := podInfo{UID: fakePodUID, ContainerID: fakeContainerID, CoreLabel: "0-3"}
+	enrichedPod := podInfo{Name: "pod-a", Namespace: "default", ContainerName: "main", UID: fakePodUID, ContainerID: fakeContainerID, CoreLabel: "0-3"}
+	assert.Equal(t, idleKey(deviceUUID, cgroupPod), idleKey(deviceUUID, enrichedPod))
+
+	// the container id identifies the allocation if the pod uid is unknown
+	assert.NotEqual(t, idleKey(deviceUUID, podInfo{ContainerID: "a", CoreLabel: "0-3"}), idleKey(deviceUUID, podInfo{ContainerID: "b", CoreLabel: "0-3"}))
+	assert.NotEqual(t, idleKey(deviceUUID, enrichedPod), idleKey(deviceUUID, podInfo{UID: fakePodUID, CoreLabel: "4-7"}))
+
+	// the names identify the allocation if neither is known
+	assert.NotEqual(t, idleKey(deviceUUID, podInfo{Name: "pod-a", Namespace: "default", ContainerName: "main", CoreLabel: "0-3"}), idleKey(deviceUUID, podInfo{Name: "pod-b", Namespace: "default", ContainerName: "main", CoreLabel: "0-3"}))
+}
+
+// utilizationErrorDevice is a device whose core utilization cannot be read.
+type utilizationErrorDevice struct {
+	smi.Device
+}
+
+func (d utilizationErrorDevice) CoreUtilization() (smi.CoreUtilization, error) {
+	return nil, errors.New("failed to read core utilization")
+}
+
+// unknownUtilizationDevice is a device which does not report the utilization of any core.
+type unknownUtilizationDevice struct {
+	smi.Device
+}
+
+func (d unknownUtilizationDevice) CoreUtilization() (smi.CoreUtilization, error) {
+	return unknownCoreUtilization{}, nil
+}
+
+type unknownCoreUtilization struct{}
+
+func (unknownCoreUtilization) PeUtilization() []smi.PeUtilization {
+	return nil
+}
+
+func TestAllocationIdleCollector_Collect(t *testing.T) {
+	devices := []smi.Device{smi.GetStaticMockDevice(smi.ArchRngd, 0)}
+	cores := newMockDeviceCores(t, smi.ArchRngd)
+
+	deviceWise, coreWise, err := buildPodInfoCache(newFakePodResources(t, "A76AAD68-6855-40B1-9E86-D080852D1C80_cores_0-3"), cores)
+	assert.NoError(t, err)
+
+	mapper := &kubeResourcesMapper{
+		enabled:         true,
+		deviceCores:     cores,
+		deviceWiseCache: deviceWise,
+		coreWiseCache:   coreWise,
+	}
+
+	// the mock cores are 50% utilized
+	collector := NewAllocationIdleCollector(devices, NewMetricFactory("", "", false), mapper, 60).(*allocationIdleCollector)
+
+	start := time.Unix(1700000000, 0)
+	now := start
+	collector.tracker = newIdleTracker(func() time.Time { return now })
+	useTestRegistry(t)
+	collector.Register()
+
+	assert.NoError(t, collector.Collect())
+	now = start.Add(10 * time.Second)
+	assert.NoError(t, collector.Collect())
+
+	expected := `
+# HELP furiosa_npu_allocated_idle_seconds_total The time during which the cores of NPU device allocated to the pod stayed under the utilization threshold (seconds)
+# TYPE furiosa_npu_allocated_idle_seconds_total counter
+furiosa_npu_allocated_idle_seconds_total{arch="rngd",container="main",core="0-3",device="npu0",firmware_version="1.6.0+c1bebfd",namespace="default",pci_bus_id="0000:27:00.0",pert_version="0.0.0+",pod="pod-a",uuid="A76AAD68-6855-40B1-9E86-D080852D1C80"} 10
+# HELP furiosa_npu_allocated_idle_since_timestamp_seconds The unix timestamp since which the cores of NPU device allocated to the pod are under the utilization threshold, exported only while idle
+# TYPE furiosa_npu_allocated_idle_since_timestamp_seconds gauge
+furiosa_npu_allocated_idle_since_timestamp_seconds{arch="rngd",container="main",core="0-3",device="npu0",firmware_version="1.6.0+c1bebfd",namespace="default",pci_bus_id="0000:27:00.0",pert_version="0.0.0+",pod="pod-a",uuid="A76AAD68-6855-40B1-9E86-D080852D1C80"} 1.7e+09
+`
+	err = testutil.GatherAndCompare(prometheus.DefaultGatherer, strings.NewReader(expected), "furiosa_npu_allocated_idle_seconds_total", "furiosa_npu_allocated_idle_since_timestamp_seconds")
+	assert.NoError(t, err)
+
+	// the busy allocation keeps the idle time without the idle since timestamp
+	collector.utilizationThreshold = 10
+	now = start.Add(20 * time.Second)
+	assert.NoError(t, collector.Collect())
+	assert.Equal(t, 1, testutil.CollectAndCount(collector.idleSecondsCounterVec))
+	assert.Equal(t, 0, testutil.CollectAndCount(collector.idleSinceGaugeVec))
+
+	// the last idle state is exported while the utilization cannot be read
+	collector.devices = []smi.Device{utilizationErrorDevice{devices[0]}}
+	now = start.Add(30 * time.Second)
+	assert.Error(t, collector.Collect())
+	assert.Equal(t, 1, testutil.CollectAndCount(collector.idleSecondsCounterVec))
+	assert.Equal(t, float64(10), testutil.ToFloat64(collector.idleSecondsCounterVec))
+
+	// and while the utilization of the allocated cores is unknown
+	collector.devices = []smi.Device{unknownUtilizationDevice{devices[0]}}
+	now = start.Add(35 * time.Second)
+	assert.NoError(t, collector.Collect())
+	assert.Equal(t, 1, testutil.CollectAndCount(collector.idleSecondsCounterVec))
+	assert.Equal(t, float64(10), testutil.ToFloat64(collector.idleSecondsCounterVec))
+
+	// a new pod on the same cores does not inherit the idle time of the replaced pod
+	collector.devices = devices
+	mapper.deviceWiseCache["A76AAD68-6855-40B1-9E86-D080852D1C80"][0].Name = "pod-b"
+	now = start.Add(40 * time.Second)
+	assert.NoError(t, collector.Collect())
+	assert.Equal(t, 1, testutil.CollectAndCount(collector.idleSecondsCounterVec))
+	assert.Equal(t, float64(0), testutil.ToFloat64(collector.idleSecondsCounterVec))
+}
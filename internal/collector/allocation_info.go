@@ -0,0 +1,82 @@
+package collector
+
+import (
+	"errors"
+
+	"github.com/furiosa-ai/furiosa-smi-go/pkg/smi"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// allocationInfoCollector exports a `furiosa_npu_allocation_info` series per device allocated to a container, carrying
+// the pod labels left out of the other metrics in the info attribution mode. It can be joined with the other metrics
+// on `uuid`.
+type allocationInfoCollector struct {
+	devices       []smi.Device
+	metricFactory MetricFactory
+	gaugeVec      *prometheus.GaugeVec
+	kubeResMapper AllocationStateMapper
+}
+
+var _ Collector = (*allocationInfoCollector)(nil)
+
+func NewAllocationInfoCollector(devices []smi.Device, metricFactory MetricFactory, kubeResMapper AllocationStateMapper) Collector {
+	return &allocationInfoCollector{
+		devices:       devices,
+		metricFactory: metricFactory,
+		kubeResMapper: kubeResMapper,
+	}
+}
+
+func (t *allocationInfoCollector) Register() {
+	opts := prometheus.GaugeOpts{
+		Name: "furiosa_npu_allocation_info",
+		Help: "The pod and container to which the cores of NPU device are allocated. The value is always 1",
+	}
+
+	t.gaugeVec = prometheus.NewGaugeVec(opts, defaultMetricLabels(t.kubeResMapper.MetricLabels()...))
+
+	prometheus.MustRegister(NewLabelFilterCollector(
+		t.gaugeVec,
+		prometheus.Opts(opts),
+		prometheus.GaugeValue,
+	))
+}
+
+func (t *allocationInfoCollector) Collect() error {
+	metricContainer := make(MetricContainer, 0, len(t.devices))
+
+	errs := make([]error, 0)
+	for _, d := range t.devices {
+		metric, err := t.metricFactory.NewDeviceWiseMetric(d)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		for _, podInformation := range t.kubeResMapper.allocatedPods(metric[uuid].(string)) {
+			metricContainer = append(metricContainer, withPodLabels(metric, podInformation, true))
+		}
+	}
+
+	if err := t.postProcess(metricContainer); err != nil {
+		errs = append(errs, err)
+	}
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	return nil
+}
+
+// postProcess exports the metrics, which are already attributed to the pods.
+func (t *allocationInfoCollector) postProcess(metrics MetricContainer) error {
+	labelNames := defaultMetricLabels(t.kubeResMapper.MetricLabels()...)
+	t.gaugeVec.Reset()
+
+	for _, metric := range metrics {
+		t.gaugeVec.With(metricLabels(labelNames, metric, nil)).Set(1)
+	}
+
+	return nil
+}
@@ -0,0 +1,82 @@
+package collector
+
+import (
+	"sync"
+)
+
+// cycleSample is a snapshot of the performance counter of a single core.
+type cycleSample struct {
+	taskExecutionCycle uint64
+	totalCycleCount    uint64
+}
+
+// busyRatioTracker keeps the previous performance counter sample of each core, and derives the busy ratio of the
+// last interval from the difference between the previous and the current samples.
+type busyRatioTracker struct {
+	sync.Mutex
+	// previous maps uuid to core index to the last performance counter sample.
+	previous map[string]map[uint32]cycleSample
+}
+
+func newBusyRatioTracker() *busyRatioTracker {
+	return &busyRatioTracker{
+		previous: make(map[string]map[uint32]cycleSample),
+	}
+}
+
+// update stores the current samples of the given device, and returns the busy ratio of each core and of the whole
+// device over the last interval. Cores without a previous sample, whose counters were reset or without elapsed cycles
+// are omitted, and the device ratio is reported only if at least one core has a ratio.
+func (b *busyRatioTracker) update(uuid string, samples map[uint32]cycleSample) (map[uint32]float64, float64, bool) {
+	b.Lock()
+	defer b.Unlock()
+
+	previous, found := b.previous[uuid]
+	b.previous[uuid] = samples
+	if !found {
+		return map[uint32]float64{}, 0, false
+	}
+
+	coreRatios := make(map[uint32]float64, len(samples))
+	var deviceTask, deviceTotal uint64
+	for coreIdx, current := range samples {
+		prev, ok := previous[coreIdx]
+		if !ok {
+			continue
+		}
+
+		task, total, ok := cycleDelta(prev, current)
+		if !ok || total == 0 {
+			continue
+		}
+
+		coreRatios[coreIdx] = busyRatio(task, total)
+		deviceTask += task
+		deviceTotal += total
+	}
+
+	if deviceTotal == 0 {
+		return coreRatios, 0, false
+	}
+
+	return coreRatios, busyRatio(deviceTask, deviceTotal), true
+}
+
+// cycleDelta returns the increments of the task execution cycle and the total cycle count between two samples. If
+// any of the counters went backwards, e.g. by a device reset, the increments are unknown and false is returned.
+func cycleDelta(prev, current cycleSample) (uint64, uint64, bool) {
+	if current.taskExecutionCycle < prev.taskExecutionCycle || current.totalCycleCount < prev.totalCycleCount {
+		return 0, 0, false
+	}
+
+	return current.taskExecutionCycle - prev.taskExecutionCycle, current.totalCycleCount - prev.totalCycleCount, true
+}
+
+func busyRatio(task, total uint64) float64 {
+	ratio := float64(task) / float64(total)
+	if ratio > 1 {
+		return 1
+	}
+
+	return ratio
+}
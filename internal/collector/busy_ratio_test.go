@@ -0,0 +1,83 @@
+package collector
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCycleDelta(t *testing.T) {
+	tests := []struct {
+		description   string
+		prev          cycleSample
+		current       cycleSample
+		expectedTask  uint64
+		expectedTotal uint64
+		expectedOk    bool
+	}{
+		{
+			description:   "monotonic increase",
+			prev:          cycleSample{taskExecutionCycle: 100, totalCycleCount: 1000},
+			current:       cycleSample{taskExecutionCycle: 150, totalCycleCount: 1200},
+			expectedTask:  50,
+			expectedTotal: 200,
+			expectedOk:    true,
+		},
+		{
+			description: "task execution cycle reset",
+			prev:        cycleSample{taskExecutionCycle: 1000, totalCycleCount: 1000},
+			current:     cycleSample{taskExecutionCycle: 20, totalCycleCount: 1200},
+		},
+		{
+			description: "total cycle count reset",
+			prev:        cycleSample{taskExecutionCycle: 100, totalCycleCount: 1000},
+			current:     cycleSample{taskExecutionCycle: 150, totalCycleCount: 20},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			task, total, ok := cycleDelta(tc.prev, tc.current)
+			assert.Equal(t, tc.expectedTask, task)
+			assert.Equal(t, tc.expectedTotal, total)
+			assert.Equal(t, tc.expectedOk, ok)
+		})
+	}
+}
+
+func TestBusyRatioTracker_Update(t *testing.T) {
+	tracker := newBusyRatioTracker()
+
+	// the first sample has nothing to compare with
+	coreRatios, _, ok := tracker.update("uuid", map[uint32]cycleSample{
+		0: {taskExecutionCycle: 0, totalCycleCount: 0},
+		1: {taskExecutionCycle: 1000, totalCycleCount: 1000},
+	})
+	assert.False(t, ok)
+	assert.Empty(t, coreRatios)
+
+	coreRatios, deviceRatio, ok := tracker.update("uuid", map[uint32]cycleSample{
+		0: {taskExecutionCycle: 250, totalCycleCount: 1000},
+		1: {taskExecutionCycle: 1750, totalCycleCount: 2000},
+	})
+	assert.True(t, ok)
+	assert.Equal(t, map[uint32]float64{0: 0.25, 1: 0.75}, coreRatios)
+	assert.Equal(t, 0.5, deviceRatio)
+
+	// core 1 is reset, so its sample is dropped
+	coreRatios, deviceRatio, ok = tracker.update("uuid", map[uint32]cycleSample{
+		0: {taskExecutionCycle: 250, totalCycleCount: 2000},
+		1: {taskExecutionCycle: 100, totalCycleCount: 1000},
+	})
+	assert.True(t, ok)
+	assert.Equal(t, map[uint32]float64{0: 0}, coreRatios)
+	assert.Equal(t, 0.0, deviceRatio)
+
+	// no cycle has elapsed
+	coreRatios, _, ok = tracker.update("uuid", map[uint32]cycleSample{
+		0: {taskExecutionCycle: 250, totalCycleCount: 2000},
+		1: {taskExecutionCycle: 100, totalCycleCount: 1000},
+	})
+	assert.False(t, ok)
+	assert.Empty(t, coreRatios)
+}
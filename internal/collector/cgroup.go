@@ -0,0 +1,133 @@
+package collector
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/furiosa-ai/furiosa-smi-go/pkg/smi"
+)
+
+var (
+	// cgroupPodUIDPattern matches the pod segment of both cgroupfs (`pod<uid>`) and systemd
+	// (`kubepods-burstable-pod<uid with underscores>.slice`) cgroup paths created by kubelet.
+	cgroupPodUIDPattern = regexp.MustCompile(`pod([0-9a-f]{8}[-_][0-9a-f]{4}[-_][0-9a-f]{4}[-_][0-9a-f]{4}[-_][0-9a-f]{12})`)
+
+	// cgroupContainerIDPattern matches the last segment of cgroupfs (`<id>`) and systemd (`cri-containerd-<id>.scope`,
+	// `crio-<id>.scope`, `docker-<id>.scope`) cgroup paths created by the container runtimes.
+	cgroupContainerIDPattern = regexp.MustCompile(`(?:^|/|-)([0-9a-f]{64})(?:\.scope)?$`)
+)
+
+// parseCgroup returns the pod uid and the container id parsed from the content of `/proc/<pid>/cgroup`. The pod uid is
+// empty for containers started outside the kubelet, such as `ctr run`.
+func parseCgroup(content string) (string, string) {
+	for _, line := range strings.Split(content, "\n") {
+		// each line is formatted as `hierarchy-ID:controller-list:cgroup-path`
+		fields := strings.SplitN(strings.TrimSpace(line), ":", 3)
+		if len(fields) != 3 {
+			continue
+		}
+
+		cgroupPath := fields[2]
+		containerMatch := cgroupContainerIDPattern.FindStringSubmatch(cgroupPath)
+		if containerMatch == nil {
+			continue
+		}
+
+		podUID := ""
+		if podMatch := cgroupPodUIDPattern.FindStringSubmatch(cgroupPath); podMatch != nil {
+			podUID = strings.ReplaceAll(podMatch[1], "_", "-")
+		}
+
+		return podUID, containerMatch[1]
+	}
+
+	return "", ""
+}
+
+// cgroupAttributor attributes the NPU devices to pods and containers by reading the cgroup of the processes holding the
+// device files, which works even when the kubelet pod resources API is unavailable.
+type cgroupAttributor struct {
+	scanner *deviceFileScanner
+}
+
+func newCgroupAttributor(procRoot string, devices []smi.Device) (*cgroupAttributor, error) {
+	scanner, err := newDeviceFileScanner(procRoot, devices)
+	if err != nil {
+		return nil, err
+	}
+
+	return &cgroupAttributor{scanner: scanner}, nil
+}
+
+func (c *cgroupAttributor) buildMultiWiseCache() (deviceWiseCache, coreWiseCache, error) {
+	type containerKey struct {
+		uuid        string
+		podUID      string
+		containerID string
+	}
+
+	heldCores := make(map[containerKey][]uint32)
+	err := c.scanner.scan(func(pid int, held map[string]deviceFileOwner) {
+		raw, err := os.ReadFile(filepath.Join(c.scanner.procRoot, strconv.Itoa(pid), "cgroup"))
+		if err != nil {
+			return
+		}
+
+		podUID, containerID := parseCgroup(string(raw))
+		if containerID == "" {
+			// the process is not running in a container
+			return
+		}
+
+		// processes of the same container are merged into a single entry
+		for uuidValue, owner := range held {
+			key := containerKey{uuid: uuidValue, podUID: podUID, containerID: containerID}
+			heldCores[key] = append(heldCores[key], owner.cores...)
+		}
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	keys := make([]containerKey, 0, len(heldCores))
+	for key := range heldCores {
+		keys = append(keys, key)
+	}
+	slices.SortFunc(keys, func(a, b containerKey) int {
+		return strings.Compare(a.uuid+a.podUID+a.containerID, b.uuid+b.podUID+b.containerID)
+	})
+
+	deviceWise := make(deviceWiseCache)
+	coreWise := make(coreWiseCache)
+	for _, key := range keys {
+		cores := uniqueSortedCores(heldCores[key])
+
+		podInformation := podInfo{
+			UID:         key.podUID,
+			ContainerID: key.containerID,
+			AllocatedPE: make([]int, 0, len(cores)),
+			CoreLabel:   formatCoreLabel(cores),
+		}
+		for _, c := range cores {
+			podInformation.AllocatedPE = append(podInformation.AllocatedPE, int(c))
+		}
+
+		// build device wise cache
+		deviceWise[key.uuid] = append(deviceWise[key.uuid], podInformation)
+
+		// build core wise cache
+		if _, ok := coreWise[key.uuid]; !ok {
+			coreWise[key.uuid] = make(coreToPodInfo)
+		}
+
+		for _, coreIdx := range podInformation.AllocatedPE {
+			coreWise[key.uuid][coreIdx] = podInformation
+		}
+	}
+
+	return deviceWise, coreWise, nil
+}
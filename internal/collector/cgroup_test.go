@@ -0,0 +1,172 @@
+package collector
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/furiosa-ai/furiosa-smi-go/pkg/smi"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+const fakeContainerID = "3f1c0b5d2a9e8f7c6b5a4d3e2f1a0b9c8d7e6f5a4b3c2d1e0f9a8b7c6d5e4f3a"
+
+func TestParseCgroup(t *testing.T) {
+	tests := []struct {
+		description         string
+		content             string
+		expectedPodUID      string
+		expectedContainerID string
+	}{
+		{
+			description:         "cgroup v2 with systemd driver and containerd",
+			content:             "0::/kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod1b2c3d4e_5f60_7a8b_9c0d_1e2f3a4b5c6d.slice/cri-containerd-" + fakeContainerID + ".scope\n",
+			expectedPodUID:      "1b2c3d4e-5f60-7a8b-9c0d-1e2f3a4b5c6d",
+			expectedContainerID: fakeContainerID,
+		},
+		{
+			description:         "cgroup v1 with cgroupfs driver",
+			content:             "12:pids:/kubepods/besteffort/pod1b2c3d4e-5f60-7a8b-9c0d-1e2f3a4b5c6d/" + fakeContainerID + "\n1:name=systemd:/kubepods/besteffort/pod1b2c3d4e-5f60-7a8b-9c0d-1e2f3a4b5c6d/" + fakeContainerID + "\n",
+			expectedPodUID:      "1b2c3d4e-5f60-7a8b-9c0d-1e2f3a4b5c6d",
+			expectedContainerID: fakeContainerID,
+		},
+		{
+			description:         "cgroup v2 with cri-o",
+			content:             "0::/kubepods.slice/kubepods-pod1b2c3d4e_5f60_7a8b_9c0d_1e2f3a4b5c6d.slice/crio-" + fakeContainerID + ".scope\n",
+			expectedPodUID:      "1b2c3d4e-5f60-7a8b-9c0d-1e2f3a4b5c6d",
+			expectedContainerID: fakeContainerID,
+		},
+		{
+			description:         "container started by ctr run",
+			content:             "0::/default/" + fakeContainerID + "\n",
+			expectedPodUID:      "",
+			expectedContainerID: fakeContainerID,
+		},
+		{
+			description:         "process running on the host",
+			content:             "0::/user.slice/user-1000.slice/session-1.scope\n",
+			expectedPodUID:      "",
+			expectedContainerID: "",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			podUID, containerID := parseCgroup(tc.content)
+			assert.Equal(t, tc.expectedPodUID, podUID)
+			assert.Equal(t, tc.expectedContainerID, containerID)
+		})
+	}
+}
+
+func writeFakeCgroup(t *testing.T, procRoot string, pid int, content string) {
+	assert.NoError(t, os.WriteFile(filepath.Join(procRoot, strconv.Itoa(pid), "cgroup"), []byte(content), 0644))
+}
+
+func TestKubeResourcesMapper_CgroupFallback(t *testing.T) {
+	procRoot := t.TempDir()
+	podCgroup := "0::/kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod1b2c3d4e_5f60_7a8b_9c0d_1e2f3a4b5c6d.slice/cri-containerd-" + fakeContainerID + ".scope\n"
+
+	// two processes of the same container hold the two halves of npu0, and a host process holds npu1
+	writeFakeProcess(t, procRoot, 100, "python", "0", "/dev/rngd/npu0pe0-3")
+	writeFakeCgroup(t, procRoot, 100, podCgroup)
+	writeFakeProcess(t, procRoot, 101, "python", "0", "/dev/rngd/npu0pe4-7")
+	writeFakeCgroup(t, procRoot, 101, podCgroup)
+	writeFakeProcess(t, procRoot, 200, "python", "0", "/dev/rngd/npu1pe0-3")
+	writeFakeCgroup(t, procRoot, 200, "0::/user.slice/user-1000.slice/session-1.scope\n")
+
+	devices := smi.GetStaticMockDevices(smi.ArchRngd)[:2]
+	attributor, err := newCgroupAttributor(procRoot, devices)
+	assert.NoError(t, err)
+
+	// the kubelet socket does not exist in the test environment, so the cache is built from the cgroup only
+	client, err := newPodResourcesClient(filepath.Join(t.TempDir(), "kubelet.sock"))
+	assert.NoError(t, err)
+	t.Cleanup(client.close)
+
+	mapper := &kubeResourcesMapper{
+		enabled:         true,
+		logger:          zerolog.Nop(),
+		client:          client,
+		now:             time.Now,
+		deviceCores:     newMockDeviceCores(t, smi.ArchRngd),
+		cgroupFallback:  attributor,
+		deviceWiseCache: make(deviceWiseCache),
+	}
+
+	mapper.syncPodInfoCache()
+
+	assert.Equal(t, deviceWiseCache{
+		"A76AAD68-6855-40B1-9E86-D080852D1C80": {
+			{
+				UID:         "1b2c3d4e-5f60-7a8b-9c0d-1e2f3a4b5c6d",
+				ContainerID: fakeContainerID,
+				AllocatedPE: []int{0, 1, 2, 3, 4, 5, 6, 7},
+				CoreLabel:   "0-7",
+			},
+		},
+	}, mapper.deviceWiseCache)
+
+	metric, err := NewMetricFactory("", "", false).NewDeviceWiseMetric(devices[0])
+	assert.NoError(t, err)
+
+	transformed := mapper.TransformDeviceMetrics(MetricContainer{metric}, false)
+	assert.Len(t, transformed, 1)
+	assert.Equal(t, "1b2c3d4e-5f60-7a8b-9c0d-1e2f3a4b5c6d", transformed[0][kubernetesPodUID])
+	assert.Equal(t, fakeContainerID, transformed[0][containerID])
+	assert.Equal(t, "", transformed[0][kubernetesPod])
+}
+
+func TestPowerCollector_CgroupFallbackEnergy(t *testing.T) {
+	procRoot := t.TempDir()
+	otherContainerID := "9a8b7c6d5e4f3a2b1c0d9e8f7a6b5c4d3e2f1a0b9c8d7e6f5a4b3c2d1e0f9a8b"
+
+	// two containers of different pods hold the two halves of npu0
+	writeFakeProcess(t, procRoot, 100, "python", "0", "/dev/rngd/npu0pe0-3")
+	writeFakeCgroup(t, procRoot, 100, "0::/kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod1b2c3d4e_5f60_7a8b_9c0d_1e2f3a4b5c6d.slice/cri-containerd-"+fakeContainerID+".scope\n")
+	writeFakeProcess(t, procRoot, 101, "python", "0", "/dev/rngd/npu0pe4-7")
+	writeFakeCgroup(t, procRoot, 101, "0::/kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod2c3d4e5f_6071_8b9c_0d1e_2f3a4b5c6d7e.slice/cri-containerd-"+otherContainerID+".scope\n")
+
+	devices := smi.GetStaticMockDevices(smi.ArchRngd)[:1]
+	attributor, err := newCgroupAttributor(procRoot, devices)
+	assert.NoError(t, err)
+
+	client, err := newPodResourcesClient(filepath.Join(t.TempDir(), "kubelet.sock"))
+	assert.NoError(t, err)
+	t.Cleanup(client.close)
+
+	kubeResMapper := &kubeResourcesMapper{
+		enabled:         true,
+		logger:          zerolog.Nop(),
+		client:          client,
+		now:             time.Now,
+		deviceCores:     newMockDeviceCores(t, smi.ArchRngd),
+		cgroupFallback:  attributor,
+		deviceWiseCache: make(deviceWiseCache),
+		coreWiseCache:   make(coreWiseCache),
+	}
+	kubeResMapper.syncPodInfoCache()
+
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	collector := newFakeEnergyPowerCollector(devices, kubeResMapper, clock)
+	assert.NoError(t, collector.Collect())
+	clock.Advance(10 * time.Second)
+	assert.NoError(t, collector.Collect())
+
+	// the mock device consumes 100W, and each container is charged for the half of the device
+	expected := `
+# HELP furiosa_npu_energy_joules_total 
+# TYPE furiosa_npu_energy_joules_total counter
+furiosa_npu_energy_joules_total{arch="rngd",core="0-7",device="npu0",firmware_version="1.6.0+c1bebfd",pci_bus_id="0000:27:00.0",pert_version="0.0.0+",uuid="A76AAD68-6855-40B1-9E86-D080852D1C80"} 1000
+furiosa_npu_energy_joules_total{arch="rngd",container_id="` + fakeContainerID + `",core="0-3",device="npu0",firmware_version="1.6.0+c1bebfd",pci_bus_id="0000:27:00.0",pert_version="0.0.0+",pod_uid="1b2c3d4e-5f60-7a8b-9c0d-1e2f3a4b5c6d",uuid="A76AAD68-6855-40B1-9E86-D080852D1C80"} 500
+furiosa_npu_energy_joules_total{arch="rngd",container_id="` + otherContainerID + `",core="4-7",device="npu0",firmware_version="1.6.0+c1bebfd",pci_bus_id="0000:27:00.0",pert_version="0.0.0+",pod_uid="2c3d4e5f-6071-8b9c-0d1e-2f3a4b5c6d7e",uuid="A76AAD68-6855-40B1-9E86-D080852D1C80"} 500
+`
+	err = testutil.CollectAndCompare(NewLabelFilterCollector(collector.energyCounterVec, prometheus.Opts{Name: "furiosa_npu_energy_joules_total"}, prometheus.CounterValue), strings.NewReader(expected), "furiosa_npu_energy_joules_total")
+	assert.NoError(t, err)
+}
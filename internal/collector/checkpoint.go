@@ -0,0 +1,168 @@
+package collector
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// kubeletCheckpoint is the device manager checkpoint written by the kubelet in the device plugins directory, such as
+// `{"Data":{"PodDeviceEntries":[{"PodUID":"...","ContainerName":"main","ResourceName":"furiosa.ai/rngd","DeviceIDs":{"-1":["<uuid>"]}}]}}`.
+type kubeletCheckpoint struct {
+	Data struct {
+		PodDeviceEntries []checkpointPodDevices `json:"PodDeviceEntries"`
+	} `json:"Data"`
+}
+
+type checkpointPodDevices struct {
+	PodUID        string `json:"PodUID"`
+	ContainerName string `json:"ContainerName"`
+	ResourceName  string `json:"ResourceName"`
+	// DeviceIDs is a list of device ids on kubelets older than 1.20, and a map of NUMA node to device ids since.
+	DeviceIDs json.RawMessage `json:"DeviceIDs"`
+}
+
+// parseCheckpointDeviceIDs returns the device ids of a checkpoint entry in either format.
+func parseCheckpointDeviceIDs(raw json.RawMessage) ([]string, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+
+	var deviceIDs []string
+	if err := json.Unmarshal(raw, &deviceIDs); err == nil {
+		return deviceIDs, nil
+	}
+
+	var numaDeviceIDs map[string][]string
+	if err := json.Unmarshal(raw, &numaDeviceIDs); err != nil {
+		return nil, fmt.Errorf("failed to parse device ids '%s'; err: %w", string(raw), err)
+	}
+
+	numaNodes := make([]string, 0, len(numaDeviceIDs))
+	for numaNode := range numaDeviceIDs {
+		numaNodes = append(numaNodes, numaNode)
+	}
+	slices.Sort(numaNodes)
+
+	for _, numaNode := range numaNodes {
+		deviceIDs = append(deviceIDs, numaDeviceIDs[numaNode]...)
+	}
+
+	return deviceIDs, nil
+}
+
+// buildCheckpointCache returns the pod information of the devices recorded in the kubelet checkpoint. The pods are
+// only known by uid, and the device ids that cannot be parsed are skipped and returned as errors.
+func buildCheckpointCache(checkpoint kubeletCheckpoint, cores deviceCores) (deviceWiseCache, coreWiseCache, error) {
+	deviceWise := make(deviceWiseCache)
+	coreWise := make(coreWiseCache)
+
+	errs := make([]error, 0)
+	for _, entry := range checkpoint.Data.PodDeviceEntries {
+		if !strings.HasPrefix(entry.ResourceName, furiosaResourcePrefix) {
+			continue
+		}
+
+		deviceIDs, err := parseCheckpointDeviceIDs(entry.DeviceIDs)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		for _, deviceID := range deviceIDs {
+			deviceUUID, allocatedPE, coreLabel, err := cores.parseDeviceID(deviceID)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+
+			podInformation := podInfo{
+				UID:           entry.PodUID,
+				ContainerName: entry.ContainerName,
+				AllocatedPE:   allocatedPE,
+				CoreLabel:     coreLabel,
+			}
+
+			// build device wise cache
+			deviceWise[deviceUUID] = append(deviceWise[deviceUUID], podInformation)
+
+			// build core wise cache
+			if _, ok := coreWise[deviceUUID]; !ok {
+				coreWise[deviceUUID] = make(coreToPodInfo)
+			}
+
+			for _, coreIdx := range podInformation.AllocatedPE {
+				coreWise[deviceUUID][coreIdx] = podInformation
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return deviceWise, coreWise, errors.Join(errs...)
+	}
+
+	return deviceWise, coreWise, nil
+}
+
+// checkpointAttributor attributes the NPU devices to the pod uid and container name recorded in the kubelet device
+// manager checkpoint, which is written even on the nodes where the pod resources API is unavailable. The file is
+// watched by its modification time and size, and parsed again only when it changes.
+type checkpointAttributor struct {
+	path  string
+	cores deviceCores
+
+	sync.Mutex
+	modTime    time.Time
+	size       int64
+	deviceWise deviceWiseCache
+	coreWise   coreWiseCache
+}
+
+func newCheckpointAttributor(path string, cores deviceCores) *checkpointAttributor {
+	return &checkpointAttributor{
+		path:  path,
+		cores: cores,
+	}
+}
+
+// buildMultiWiseCache returns the pod information of the last checkpoint, and the modification time of the checkpoint.
+// The pod information of the previous checkpoint is kept while the file cannot be read or parsed, e.g. while the
+// kubelet is rewriting it.
+func (c *checkpointAttributor) buildMultiWiseCache() (deviceWiseCache, coreWiseCache, time.Time, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	info, err := os.Stat(c.path)
+	if err != nil {
+		return c.deviceWise, c.coreWise, c.modTime, fmt.Errorf("kubelet checkpoint '%s' does not exist; err: %w", c.path, err)
+	}
+
+	if c.deviceWise != nil && info.ModTime().Equal(c.modTime) && info.Size() == c.size {
+		return c.deviceWise, c.coreWise, c.modTime, nil
+	}
+
+	raw, err := os.ReadFile(c.path)
+	if err != nil {
+		return c.deviceWise, c.coreWise, c.modTime, fmt.Errorf("failed to read kubelet checkpoint '%s'; err: %w", c.path, err)
+	}
+
+	var checkpoint kubeletCheckpoint
+	if err := json.Unmarshal(raw, &checkpoint); err != nil {
+		return c.deviceWise, c.coreWise, c.modTime, fmt.Errorf("failed to parse kubelet checkpoint '%s'; err: %w", c.path, err)
+	}
+
+	// the malformed device ids are skipped, so the rest of the checkpoint is still used
+	deviceWise, coreWise, err := buildCheckpointCache(checkpoint, c.cores)
+
+	c.modTime = info.ModTime()
+	c.size = info.Size()
+	c.deviceWise = deviceWise
+	c.coreWise = coreWise
+
+	return deviceWise, coreWise, c.modTime, err
+}
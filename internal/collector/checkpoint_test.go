@@ -0,0 +1,196 @@
+package collector
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/furiosa-ai/furiosa-smi-go/pkg/smi"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	podResourcesV1Alpha1API "k8s.io/kubelet/pkg/apis/podresources/v1alpha1"
+)
+
+// writeFakeCheckpoint writes a kubelet checkpoint recording the given device ids for the container "main" of the pod
+// fakePodUID, in the format of the kubelets since 1.20.
+func writeFakeCheckpoint(t *testing.T, path string, deviceIDs ...string) {
+	t.Helper()
+
+	raw := `{"Data":{"PodDeviceEntries":[` +
+		`{"PodUID":"` + fakePodUID + `","ContainerName":"main","ResourceName":"furiosa.ai/rngd","DeviceIDs":{"-1":` + mustMarshal(t, deviceIDs) + `},"AllocResp":"CgA="},` +
+		`{"PodUID":"8a9b0c1d-2e3f-4a5b-6c7d-8e9f0a1b2c3d","ContainerName":"gpu","ResourceName":"nvidia.com/gpu","DeviceIDs":{"0":["GPU-0"]},"AllocResp":"CgA="}` +
+		`],"RegisteredDevices":{"furiosa.ai/rngd":["A76AAD68-6855-40B1-9E86-D080852D1C80"]}},"Checksum":1234}`
+
+	assert.NoError(t, os.WriteFile(path, []byte(raw), 0o600))
+}
+
+func mustMarshal(t *testing.T, v interface{}) string {
+	raw, err := json.Marshal(v)
+	assert.NoError(t, err)
+
+	return string(raw)
+}
+
+func TestParseCheckpointDeviceIDs(t *testing.T) {
+	// kubelets older than 1.20 record a list of device ids
+	deviceIDs, err := parseCheckpointDeviceIDs(json.RawMessage(`["a","b"]`))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, deviceIDs)
+
+	deviceIDs, err = parseCheckpointDeviceIDs(json.RawMessage(`{"1":["c"],"0":["a","b"]}`))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, deviceIDs)
+
+	deviceIDs, err = parseCheckpointDeviceIDs(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, deviceIDs)
+
+	_, err = parseCheckpointDeviceIDs(json.RawMessage(`"a"`))
+	assert.Error(t, err)
+}
+
+func TestCheckpointAttributor_BuildMultiWiseCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "kubelet_internal_checkpoint")
+	attributor := newCheckpointAttributor(path, newMockDeviceCores(t, smi.ArchRngd))
+
+	_, _, _, err := attributor.buildMultiWiseCache()
+	assert.Error(t, err, "the checkpoint does not exist")
+
+	writeFakeCheckpoint(t, path, "A76AAD68-6855-40B1-9E86-D080852D1C80_cores_0-3")
+
+	deviceWise, coreWise, modTime, err := attributor.buildMultiWiseCache()
+	assert.NoError(t, err)
+	assert.False(t, modTime.IsZero())
+	assert.Equal(t, deviceWiseCache{
+		"A76AAD68-6855-40B1-9E86-D080852D1C80": {
+			{UID: fakePodUID, ContainerName: "main", AllocatedPE: []int{0, 1, 2, 3}, CoreLabel: "0-3"},
+		},
+	}, deviceWise)
+	assert.Len(t, coreWise["A76AAD68-6855-40B1-9E86-D080852D1C80"], 4)
+
+	// the checkpoint is parsed again once modified
+	writeFakeCheckpoint(t, path, "A76AAD68-6855-40B1-9E86-D080852D1C80_cores_0-3", "A76AAD68-6855-40B1-9E86-D080852D1C81")
+	assert.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(time.Second)))
+
+	deviceWise, _, _, err = attributor.buildMultiWiseCache()
+	assert.NoError(t, err)
+	assert.Len(t, deviceWise, 2)
+
+	// the previous pod information is kept while the checkpoint is malformed
+	assert.NoError(t, os.WriteFile(path, []byte(`{"Data":`), 0o600))
+
+	deviceWise, _, _, err = attributor.buildMultiWiseCache()
+	assert.Error(t, err)
+	assert.Len(t, deviceWise, 2)
+}
+
+func TestKubeResourcesMapper_CheckpointFallback(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "kubelet_internal_checkpoint")
+	writeFakeCheckpoint(t, path, "A76AAD68-6855-40B1-9E86-D080852D1C80")
+
+	// the pod resources socket is missing
+	client, err := newPodResourcesClient(filepath.Join(t.TempDir(), "kubelet.sock"))
+	assert.NoError(t, err)
+	t.Cleanup(client.close)
+
+	cores := newMockDeviceCores(t, smi.ArchRngd)
+	mapper := &kubeResourcesMapper{
+		enabled:         true,
+		logger:          zerolog.Nop(),
+		client:          client,
+		now:             time.Now,
+		deviceCores:     cores,
+		checkpoint:      newCheckpointAttributor(path, cores),
+		deviceWiseCache: make(deviceWiseCache),
+	}
+
+	mapper.syncPodInfoCache()
+	assert.Equal(t, []podInfo{
+		{UID: fakePodUID, ContainerName: "main", AllocatedPE: cores["A76AAD68-6855-40B1-9E86-D080852D1C80"], CoreLabel: "0-7"},
+	}, mapper.allocatedPods("A76AAD68-6855-40B1-9E86-D080852D1C80"))
+
+	// the pod is named by the pod informer
+	mapper.podMetadata = startFakePodMetadataInformer(t, PodMetadataOptions{Enabled: true, NodeName: "node-a"}, newFakePod("pod-a", "node-a"))
+
+	mapper.syncPodInfoCache()
+	assert.Equal(t, []podInfo{
+		{Name: "pod-a", Namespace: "default", UID: fakePodUID, ContainerName: "main", AllocatedPE: cores["A76AAD68-6855-40B1-9E86-D080852D1C80"], CoreLabel: "0-7"},
+	}, mapper.allocatedPods("A76AAD68-6855-40B1-9E86-D080852D1C80"))
+
+	// the checkpoint cache is not modified by the informer
+	deviceWise, _, _, err := mapper.checkpoint.buildMultiWiseCache()
+	assert.NoError(t, err)
+	assert.Empty(t, deviceWise["A76AAD68-6855-40B1-9E86-D080852D1C80"][0].Name)
+}
+
+func TestKubeResourcesMapper_CheckpointFresherThanKubeletCache(t *testing.T) {
+	socket, server := serveFakeKubelet(t, func(s *grpc.Server) {
+		podResourcesV1Alpha1API.RegisterPodResourcesListerServer(s, &fakeV1Alpha1PodResourcesServer{
+			resp: &podResourcesV1Alpha1API.ListPodResourcesResponse{
+				PodResources: []*podResourcesV1Alpha1API.PodResources{
+					{
+						Name:      "pod-a",
+						Namespace: "default",
+						Containers: []*podResourcesV1Alpha1API.ContainerResources{
+							{
+								Name: "main",
+								Devices: []*podResourcesV1Alpha1API.ContainerDevices{
+									{ResourceName: "furiosa.ai/rngd", DeviceIds: []string{"A76AAD68-6855-40B1-9E86-D080852D1C80"}},
+								},
+							},
+						},
+					},
+				},
+			},
+		})
+	})
+
+	client, err := newPodResourcesClient(socket)
+	assert.NoError(t, err)
+	t.Cleanup(client.close)
+
+	path := filepath.Join(t.TempDir(), "kubelet_internal_checkpoint")
+	cores := newMockDeviceCores(t, smi.ArchRngd)
+
+	var logs bytes.Buffer
+	now := time.Now()
+	mapper := &kubeResourcesMapper{
+		enabled:         true,
+		logger:          zerolog.New(&logs),
+		client:          client,
+		cacheTTL:        5 * time.Minute,
+		now:             func() time.Time { return now },
+		deviceCores:     cores,
+		checkpoint:      newCheckpointAttributor(path, cores),
+		deviceWiseCache: make(deviceWiseCache),
+	}
+
+	mapper.syncPodInfoCache()
+	assert.Len(t, mapper.allocatedPods("A76AAD68-6855-40B1-9E86-D080852D1C80"), 1)
+
+	// the kubelet is gone, and the checkpoint older than its last response is ignored within the ttl
+	server.Stop()
+	writeFakeCheckpoint(t, path, "A76AAD68-6855-40B1-9E86-D080852D1C81")
+	assert.NoError(t, os.Chtimes(path, now.Add(-time.Minute), now.Add(-time.Minute)))
+
+	now = now.Add(time.Second)
+	mapper.syncPodInfoCache()
+	assert.Len(t, mapper.allocatedPods("A76AAD68-6855-40B1-9E86-D080852D1C80"), 1)
+	assert.Empty(t, mapper.allocatedPods("A76AAD68-6855-40B1-9E86-D080852D1C81"))
+
+	// the checkpoint rewritten since the last kubelet response wins over the kept pod information
+	assert.NoError(t, os.Chtimes(path, now, now))
+
+	now = now.Add(time.Second)
+	mapper.syncPodInfoCache()
+	assert.Empty(t, mapper.allocatedPods("A76AAD68-6855-40B1-9E86-D080852D1C80"))
+	assert.Len(t, mapper.allocatedPods("A76AAD68-6855-40B1-9E86-D080852D1C81"), 1)
+
+	// the missing kubelet socket is only reported once
+	assert.Equal(t, 1, strings.Count(logs.String(), "kubelet socket does not exist"))
+}
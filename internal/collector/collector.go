@@ -1,5 +1,7 @@
 package collector
 
+import "context"
+
 type Metric map[string]interface{}
 
 type MetricContainer []Metric
@@ -13,3 +15,9 @@ type Collector interface {
 	// PostProcess performs any post-processing of raw data before flushing metrics
 	postProcess(metrics MetricContainer) error
 }
+
+// BackgroundSampler is implemented by the collectors sampling the devices in the background between two collections.
+type BackgroundSampler interface {
+	// StartSampling starts the background sampling until the context is done.
+	StartSampling(ctx context.Context)
+}
@@ -0,0 +1,19 @@
+package collector
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// useTestRegistry replaces the default registry with a fresh one for the test, so that the collectors can be
+// registered again when the tests are repeated.
+func useTestRegistry(t *testing.T) {
+	registerer, gatherer := prometheus.DefaultRegisterer, prometheus.DefaultGatherer
+	registry := prometheus.NewRegistry()
+	prometheus.DefaultRegisterer, prometheus.DefaultGatherer = registry, registry
+
+	t.Cleanup(func() {
+		prometheus.DefaultRegisterer, prometheus.DefaultGatherer = registerer, gatherer
+	})
+}
@@ -0,0 +1,119 @@
+package collector
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/furiosa-ai/furiosa-smi-go/pkg/smi"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	peStatus = "peStatus"
+
+	coreStatusAvailable = "available"
+	coreStatusOccupied  = "occupied"
+	coreStatusUnknown   = "unknown"
+)
+
+type coreStatusCollector struct {
+	devices       []smi.Device
+	metricFactory MetricFactory
+	gaugeVec      *prometheus.GaugeVec
+	kubeResMapper KubeResourcesMapper
+}
+
+var _ Collector = (*coreStatusCollector)(nil)
+
+func NewCoreStatusCollector(devices []smi.Device, metricFactory MetricFactory, kubeResMapper KubeResourcesMapper) Collector {
+	return &coreStatusCollector{
+		devices:       devices,
+		metricFactory: metricFactory,
+		kubeResMapper: kubeResMapper,
+	}
+}
+
+func (t *coreStatusCollector) Register() {
+	opts := prometheus.GaugeOpts{
+		Name: "furiosa_npu_core_status",
+		Help: "The occupation status of NPU device core (1 if occupied by a process, 0 otherwise)",
+	}
+
+	t.gaugeVec = prometheus.NewGaugeVec(opts, append(defaultMetricLabels(t.kubeResMapper.MetricLabels()...), state))
+
+	prometheus.MustRegister(NewLabelFilterCollector(
+		t.gaugeVec,
+		prometheus.Opts(opts),
+		prometheus.GaugeValue,
+	))
+}
+
+func (t *coreStatusCollector) Collect() error {
+	metricContainer := make(MetricContainer, 0, len(t.devices))
+
+	errs := make([]error, 0)
+	for _, d := range t.devices {
+		metric, err := t.metricFactory.NewDeviceWiseMetric(d)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		coreStatus, err := d.CoreStatus()
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		for _, pe := range coreStatus.PeStatus() {
+			duplicated := deepCopyMetric(metric)
+			duplicated[core] = strconv.Itoa(int(pe.Core()))
+			duplicated[peStatus] = pe.Status()
+			metricContainer = append(metricContainer, duplicated)
+		}
+	}
+
+	if err := t.postProcess(metricContainer); err != nil {
+		errs = append(errs, err)
+	}
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	return nil
+}
+
+func (t *coreStatusCollector) postProcess(metrics MetricContainer) error {
+	labelNames := defaultMetricLabels(t.kubeResMapper.MetricLabels()...)
+	transformed := t.kubeResMapper.TransformDeviceMetrics(metrics, true)
+	t.gaugeVec.Reset()
+
+	for _, metric := range transformed {
+		if value, ok := metric[peStatus]; ok {
+			status := value.(smi.CoreStatus)
+
+			var occupied float64
+			if status == smi.CoreStatusOccupied {
+				occupied = 1
+			} else {
+				occupied = 0
+			}
+
+			t.gaugeVec.With(metricLabels(labelNames, metric, prometheus.Labels{state: coreStatusToString(status)})).Set(occupied)
+		}
+	}
+
+	return nil
+}
+
+func coreStatusToString(status smi.CoreStatus) string {
+	switch status {
+	case smi.CoreStatusAvailable:
+		return coreStatusAvailable
+	case smi.CoreStatusOccupied:
+		return coreStatusOccupied
+	default:
+		return coreStatusUnknown
+	}
+}
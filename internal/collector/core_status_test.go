@@ -0,0 +1,102 @@
+package collector
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/furiosa-ai/furiosa-smi-go/pkg/smi"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func newFakeCoreStatusCollector() Collector {
+	return &coreStatusCollector{
+		devices:       nil,
+		metricFactory: nil,
+		kubeResMapper: NewFakeKubeResourcesMapper(),
+	}
+}
+
+func TestCoreStatusCollector_PostProcessing(t *testing.T) {
+	tests := []struct {
+		description string
+		source      MetricContainer
+		expected    string
+	}{
+		{
+			description: "half of cores are occupied",
+			source: func() MetricContainer {
+				tc := MetricContainer{}
+				for i := 0; i < 8; i++ {
+					metric := newMetric()
+					metric[arch] = "rngd"
+					metric[core] = strconv.Itoa(i)
+					metric[device] = "npu0"
+					metric[uuid] = "uuid"
+					metric[bdf] = "bdf"
+					if i < 4 {
+						metric[peStatus] = smi.CoreStatusOccupied
+					} else {
+						metric[peStatus] = smi.CoreStatusAvailable
+					}
+					tc = append(tc, metric)
+				}
+				return tc
+			}(),
+			expected: `
+# HELP furiosa_npu_core_status The occupation status of NPU device core (1 if occupied by a process, 0 otherwise)
+# TYPE furiosa_npu_core_status gauge
+furiosa_npu_core_status{arch="rngd",core="0",device="npu0",pci_bus_id="bdf",state="occupied",uuid="uuid"} 1
+furiosa_npu_core_status{arch="rngd",core="1",device="npu0",pci_bus_id="bdf",state="occupied",uuid="uuid"} 1
+furiosa_npu_core_status{arch="rngd",core="2",device="npu0",pci_bus_id="bdf",state="occupied",uuid="uuid"} 1
+furiosa_npu_core_status{arch="rngd",core="3",device="npu0",pci_bus_id="bdf",state="occupied",uuid="uuid"} 1
+furiosa_npu_core_status{arch="rngd",core="4",device="npu0",pci_bus_id="bdf",state="available",uuid="uuid"} 0
+furiosa_npu_core_status{arch="rngd",core="5",device="npu0",pci_bus_id="bdf",state="available",uuid="uuid"} 0
+furiosa_npu_core_status{arch="rngd",core="6",device="npu0",pci_bus_id="bdf",state="available",uuid="uuid"} 0
+furiosa_npu_core_status{arch="rngd",core="7",device="npu0",pci_bus_id="bdf",state="available",uuid="uuid"} 0
+`,
+		},
+	}
+
+	collector := newFakeCoreStatusCollector()
+	useTestRegistry(t)
+	collector.Register()
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			err := collector.postProcess(tc.source)
+			assert.NoError(t, err)
+
+			err = testutil.GatherAndCompare(prometheus.DefaultGatherer, strings.NewReader(head+tc.expected), "furiosa_npu_core_status")
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestCoreStatusCollector_Collect(t *testing.T) {
+	devices := []smi.Device{smi.GetStaticMockDevice(smi.ArchRngd, 0)}
+
+	collector := NewCoreStatusCollector(devices, NewMetricFactory("", "", false), NewFakeKubeResourcesMapper())
+	useTestRegistry(t)
+	collector.Register()
+
+	err := collector.Collect()
+	assert.NoError(t, err)
+
+	expected := `
+# HELP furiosa_npu_core_status The occupation status of NPU device core (1 if occupied by a process, 0 otherwise)
+# TYPE furiosa_npu_core_status gauge
+furiosa_npu_core_status{arch="rngd",core="0",device="npu0",firmware_version="1.6.0+c1bebfd",pci_bus_id="0000:27:00.0",pert_version="0.0.0+",state="available",uuid="A76AAD68-6855-40B1-9E86-D080852D1C80"} 0
+furiosa_npu_core_status{arch="rngd",core="1",device="npu0",firmware_version="1.6.0+c1bebfd",pci_bus_id="0000:27:00.0",pert_version="0.0.0+",state="available",uuid="A76AAD68-6855-40B1-9E86-D080852D1C80"} 0
+furiosa_npu_core_status{arch="rngd",core="2",device="npu0",firmware_version="1.6.0+c1bebfd",pci_bus_id="0000:27:00.0",pert_version="0.0.0+",state="available",uuid="A76AAD68-6855-40B1-9E86-D080852D1C80"} 0
+furiosa_npu_core_status{arch="rngd",core="3",device="npu0",firmware_version="1.6.0+c1bebfd",pci_bus_id="0000:27:00.0",pert_version="0.0.0+",state="available",uuid="A76AAD68-6855-40B1-9E86-D080852D1C80"} 0
+furiosa_npu_core_status{arch="rngd",core="4",device="npu0",firmware_version="1.6.0+c1bebfd",pci_bus_id="0000:27:00.0",pert_version="0.0.0+",state="available",uuid="A76AAD68-6855-40B1-9E86-D080852D1C80"} 0
+furiosa_npu_core_status{arch="rngd",core="5",device="npu0",firmware_version="1.6.0+c1bebfd",pci_bus_id="0000:27:00.0",pert_version="0.0.0+",state="available",uuid="A76AAD68-6855-40B1-9E86-D080852D1C80"} 0
+furiosa_npu_core_status{arch="rngd",core="6",device="npu0",firmware_version="1.6.0+c1bebfd",pci_bus_id="0000:27:00.0",pert_version="0.0.0+",state="available",uuid="A76AAD68-6855-40B1-9E86-D080852D1C80"} 0
+furiosa_npu_core_status{arch="rngd",core="7",device="npu0",firmware_version="1.6.0+c1bebfd",pci_bus_id="0000:27:00.0",pert_version="0.0.0+",state="available",uuid="A76AAD68-6855-40B1-9E86-D080852D1C80"} 0
+`
+	err = testutil.GatherAndCompare(prometheus.DefaultGatherer, strings.NewReader(expected), "furiosa_npu_core_status")
+	assert.NoError(t, err)
+}
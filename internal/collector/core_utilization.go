@@ -9,23 +9,29 @@ import (
 )
 
 const (
-	peUtilization = "peUtilization"
+	peUtilization       = "peUtilization"
+	peUtilizationWindow = "peUtilizationWindow"
+	deviceUtilization   = "deviceUtilization"
 )
 
 type coreUtilizationCollector struct {
-	devices       []smi.Device
-	metricFactory MetricFactory
-	gaugeVec      *prometheus.GaugeVec
-	kubeResMapper KubeResourcesMapper
+	devices                   []smi.Device
+	metricFactory             MetricFactory
+	gaugeVec                  *prometheus.GaugeVec
+	windowGaugeVec            *prometheus.GaugeVec
+	deviceUtilizationGaugeVec *prometheus.GaugeVec
+	kubeResMapper             KubeResourcesMapper
+	samples                   *DeviceSamples
 }
 
 var _ Collector = (*coreUtilizationCollector)(nil)
 
-func NewCoreUtilizationCollector(devices []smi.Device, metricFactory MetricFactory, kubeResMapper KubeResourcesMapper) Collector {
+func NewCoreUtilizationCollector(devices []smi.Device, metricFactory MetricFactory, kubeResMapper KubeResourcesMapper, samples *DeviceSamples) Collector {
 	return &coreUtilizationCollector{
 		devices:       devices,
 		metricFactory: metricFactory,
 		kubeResMapper: kubeResMapper,
+		samples:       samples,
 	}
 }
 
@@ -35,13 +41,39 @@ func (t *coreUtilizationCollector) Register() {
 		Help: "The current core utilization of NPU device",
 	}
 
-	t.gaugeVec = prometheus.NewGaugeVec(opts, defaultMetricLabels())
+	t.gaugeVec = prometheus.NewGaugeVec(opts, defaultMetricLabels(t.kubeResMapper.MetricLabels()...))
 
 	prometheus.MustRegister(NewLabelFilterCollector(
 		t.gaugeVec,
 		prometheus.Opts(opts),
 		prometheus.GaugeValue,
 	))
+
+	windowOpts := prometheus.GaugeOpts{
+		Name: "furiosa_npu_core_utilization_window_seconds",
+		Help: "The time window over which the core utilization of NPU device is measured (seconds)",
+	}
+
+	t.windowGaugeVec = prometheus.NewGaugeVec(windowOpts, defaultMetricLabels(t.kubeResMapper.MetricLabels()...))
+
+	prometheus.MustRegister(NewLabelFilterCollector(
+		t.windowGaugeVec,
+		prometheus.Opts(windowOpts),
+		prometheus.GaugeValue,
+	))
+
+	deviceUtilizationOpts := prometheus.GaugeOpts{
+		Name: "furiosa_npu_utilization",
+		Help: "The current utilization of NPU device averaged over the cores",
+	}
+
+	t.deviceUtilizationGaugeVec = prometheus.NewGaugeVec(deviceUtilizationOpts, defaultMetricLabels(t.kubeResMapper.MetricLabels()...))
+
+	prometheus.MustRegister(NewLabelFilterCollector(
+		t.deviceUtilizationGaugeVec,
+		prometheus.Opts(deviceUtilizationOpts),
+		prometheus.GaugeValue,
+	))
 }
 
 func (t *coreUtilizationCollector) Collect() error {
@@ -57,17 +89,29 @@ func (t *coreUtilizationCollector) Collect() error {
 
 		coreUtilization, err := d.CoreUtilization()
 		if err != nil {
+			t.samples.forgetUtilizations(metric[uuid].(string))
 			errs = append(errs, err)
 			continue
 		}
 
 		utilization := coreUtilization.PeUtilization()
+		utilizations := make(map[int]float64, len(utilization))
 		for _, pe := range utilization {
+			utilizations[int(pe.Core())] = pe.PeUsagePercentage()
+
 			duplicated := deepCopyMetric(metric)
 			duplicated[core] = strconv.Itoa(int(pe.Core()))
 			duplicated[peUtilization] = pe.PeUsagePercentage()
+			duplicated[peUtilizationWindow] = float64(pe.TimeWindowMill()) / 1000
 			metricContainer = append(metricContainer, duplicated)
 		}
+
+		t.samples.setUtilizations(metric[uuid].(string), utilizations)
+
+		if len(utilization) > 0 {
+			metric[deviceUtilization] = true
+			metricContainer = append(metricContainer, metric)
+		}
 	}
 
 	if err := t.postProcess(metricContainer); err != nil {
@@ -82,27 +126,75 @@ func (t *coreUtilizationCollector) Collect() error {
 }
 
 func (t *coreUtilizationCollector) postProcess(metrics MetricContainer) error {
-	transformed := t.kubeResMapper.TransformDeviceMetrics(metrics, true)
+	labelNames := defaultMetricLabels(t.kubeResMapper.MetricLabels()...)
 	t.gaugeVec.Reset()
+	t.windowGaugeVec.Reset()
+	t.deviceUtilizationGaugeVec.Reset()
+
+	// core utilizations by uuid and core index, used to average the utilization over the cores of each device wise series
+	coreUtilizations := make(map[string]map[int]float64)
+	coreWiseMetrics := make(MetricContainer, 0, len(metrics))
+	deviceWiseMetrics := make(MetricContainer, 0)
+	for _, metric := range metrics {
+		if _, ok := metric[deviceUtilization]; ok {
+			deviceWiseMetrics = append(deviceWiseMetrics, metric)
+			continue
+		}
 
+		coreWiseMetrics = append(coreWiseMetrics, metric)
+		if value, ok := metric[peUtilization]; ok {
+			coreIdx, err := strconv.Atoi(metric[core].(string))
+			if err != nil {
+				continue
+			}
+
+			uuidValue := metric[uuid].(string)
+			if _, found := coreUtilizations[uuidValue]; !found {
+				coreUtilizations[uuidValue] = make(map[int]float64)
+			}
+			coreUtilizations[uuidValue][coreIdx] = value.(float64)
+		}
+	}
+
+	transformed := t.kubeResMapper.TransformDeviceMetrics(coreWiseMetrics, true)
 	for _, metric := range transformed {
+		labels := metricLabels(labelNames, metric, nil)
+
 		if value, ok := metric[peUtilization]; ok {
-			t.gaugeVec.With(prometheus.Labels{
-				arch:                metric[arch].(string),
-				core:                metric[core].(string),
-				device:              metric[device].(string),
-				uuid:                metric[uuid].(string),
-				bdf:                 metric[bdf].(string),
-				firmwareVersion:     metric[firmwareVersion].(string),
-				pertVersion:         metric[pertVersion].(string),
-				driverVersion:       metric[driverVersion].(string),
-				hostname:            metric[hostname].(string),
-				kubernetesNamespace: metric[kubernetesNamespace].(string),
-				kubernetesPod:       metric[kubernetesPod].(string),
-				kubernetesContainer: metric[kubernetesContainer].(string),
-			}).Set(value.(float64))
+			t.gaugeVec.With(labels).Set(value.(float64))
+		}
+
+		if value, ok := metric[peUtilizationWindow]; ok {
+			t.windowGaugeVec.With(labels).Set(value.(float64))
 		}
 	}
 
+	for _, metric := range t.kubeResMapper.TransformDeviceMetrics(deviceWiseMetrics, false) {
+		value, ok := averageCoreUtilization(coreUtilizations[metric[uuid].(string)], metric[core].(string))
+		if !ok {
+			continue
+		}
+
+		t.deviceUtilizationGaugeVec.With(metricLabels(labelNames, metric, nil)).Set(value)
+	}
+
 	return nil
 }
+
+// averageCoreUtilization averages the utilization of the cores denoted by coreLabel.
+func averageCoreUtilization(utilizations map[int]float64, coreLabel string) (float64, bool) {
+	var sum float64
+	var count int
+	for _, coreIdx := range parseCoreLabel(coreLabel) {
+		if value, ok := utilizations[coreIdx]; ok {
+			sum += value
+			count++
+		}
+	}
+
+	if count == 0 {
+		return 0, false
+	}
+
+	return sum / float64(count), true
+}
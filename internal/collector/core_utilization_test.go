@@ -5,6 +5,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/furiosa-ai/furiosa-smi-go/pkg/smi"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -53,11 +54,57 @@ furiosa_npu_core_utilization{arch="rngd",core="5",device="npu0",pci_bus_id="bdf"
 furiosa_npu_core_utilization{arch="rngd",core="6",device="npu0",pci_bus_id="bdf",uuid="uuid"} 90
 furiosa_npu_core_utilization{arch="rngd",core="7",device="npu0",pci_bus_id="bdf",uuid="uuid"} 90
 
+`,
+		},
+		{
+			description: "core utilization metrics with window and device utilization",
+			source: func() MetricContainer {
+				tc := MetricContainer{}
+				for i := 0; i < 4; i++ {
+					metric := newMetric()
+					metric[arch] = "rngd"
+					metric[core] = strconv.Itoa(i)
+					metric[device] = "npu0"
+					metric[uuid] = "uuid"
+					metric[bdf] = "bdf"
+					metric[peUtilization] = float64(i * 20)
+					metric[peUtilizationWindow] = float64(1)
+					tc = append(tc, metric)
+				}
+
+				metric := newMetric()
+				metric[arch] = "rngd"
+				metric[core] = "0-3"
+				metric[device] = "npu0"
+				metric[uuid] = "uuid"
+				metric[bdf] = "bdf"
+				metric[deviceUtilization] = true
+				tc = append(tc, metric)
+
+				return tc
+			}(),
+			expected: `
+# HELP furiosa_npu_core_utilization The current core utilization of NPU device
+# TYPE furiosa_npu_core_utilization gauge
+furiosa_npu_core_utilization{arch="rngd",core="0",device="npu0",pci_bus_id="bdf",uuid="uuid"} 0
+furiosa_npu_core_utilization{arch="rngd",core="1",device="npu0",pci_bus_id="bdf",uuid="uuid"} 20
+furiosa_npu_core_utilization{arch="rngd",core="2",device="npu0",pci_bus_id="bdf",uuid="uuid"} 40
+furiosa_npu_core_utilization{arch="rngd",core="3",device="npu0",pci_bus_id="bdf",uuid="uuid"} 60
+# HELP furiosa_npu_core_utilization_window_seconds The time window over which the core utilization of NPU device is measured (seconds)
+# TYPE furiosa_npu_core_utilization_window_seconds gauge
+furiosa_npu_core_utilization_window_seconds{arch="rngd",core="0",device="npu0",pci_bus_id="bdf",uuid="uuid"} 1
+furiosa_npu_core_utilization_window_seconds{arch="rngd",core="1",device="npu0",pci_bus_id="bdf",uuid="uuid"} 1
+furiosa_npu_core_utilization_window_seconds{arch="rngd",core="2",device="npu0",pci_bus_id="bdf",uuid="uuid"} 1
+furiosa_npu_core_utilization_window_seconds{arch="rngd",core="3",device="npu0",pci_bus_id="bdf",uuid="uuid"} 1
+# HELP furiosa_npu_utilization The current utilization of NPU device averaged over the cores
+# TYPE furiosa_npu_utilization gauge
+furiosa_npu_utilization{arch="rngd",core="0-3",device="npu0",pci_bus_id="bdf",uuid="uuid"} 30
 `,
 		},
 	}
 
 	collector := newFakeCoreUtilizationCollector()
+	useTestRegistry(t)
 	collector.Register()
 
 	for _, tc := range tests {
@@ -65,12 +112,60 @@ furiosa_npu_core_utilization{arch="rngd",core="7",device="npu0",pci_bus_id="bdf"
 			err := collector.postProcess(tc.source)
 			assert.NoError(t, err)
 
-			err = testutil.GatherAndCompare(prometheus.DefaultGatherer, strings.NewReader(head+tc.expected), "furiosa_npu_core_utilization")
+			err = testutil.GatherAndCompare(prometheus.DefaultGatherer, strings.NewReader(head+tc.expected), "furiosa_npu_core_utilization", "furiosa_npu_core_utilization_window_seconds", "furiosa_npu_utilization")
 			assert.NoError(t, err)
 		})
 	}
 }
 
+func TestAverageCoreUtilization(t *testing.T) {
+	utilizations := map[int]float64{0: 10, 1: 20, 2: 30, 3: 40, 4: 90, 5: 90, 6: 90, 7: 90}
+
+	value, ok := averageCoreUtilization(utilizations, "0-3")
+	assert.True(t, ok)
+	assert.Equal(t, float64(25), value)
+
+	value, ok = averageCoreUtilization(utilizations, "4-7")
+	assert.True(t, ok)
+	assert.Equal(t, float64(90), value)
+
+	value, ok = averageCoreUtilization(utilizations, "0-7")
+	assert.True(t, ok)
+	assert.Equal(t, 57.5, value)
+
+	_, ok = averageCoreUtilization(utilizations, "8-9")
+	assert.False(t, ok)
+}
+
 func TestCoreUtilizationCollector_Collect(t *testing.T) {
 	//TODO: add test cases with mock device data
 }
+
+func TestAverageCoreUtilization_SplitAttribution(t *testing.T) {
+	deviceUUID := "A76AAD68-6855-40B1-9E86-D080852D1C80"
+	mapper := &kubeResourcesMapper{
+		enabled:         true,
+		deviceCores:     newMockDeviceCores(t, smi.ArchRngd),
+		attributionMode: AttributionModeSplit,
+		deviceWiseCache: deviceWiseCache{
+			deviceUUID: {
+				{Name: "pod-a", Namespace: "default", ContainerName: "main", AllocatedPE: []int{0, 1, 2, 3}, CoreLabel: "0-3"},
+				{Name: "pod-b", Namespace: "default", ContainerName: "main", AllocatedPE: []int{4, 5}, CoreLabel: "4-5"},
+			},
+		},
+	}
+
+	metric, err := NewMetricFactory("", "", false).NewDeviceWiseMetric(smi.GetStaticMockDevice(smi.ArchRngd, 0))
+	assert.NoError(t, err)
+	metric[deviceUtilization] = true
+
+	// the device utilization of each pod is averaged over its allocated cores, rather than split
+	utilizations := map[int]float64{0: 0, 1: 20, 2: 40, 3: 60, 4: 80, 5: 100, 6: 0, 7: 0}
+	actual := make(map[string]float64)
+	for _, transformed := range mapper.TransformDeviceMetrics(MetricContainer{metric}, false) {
+		value, ok := averageCoreUtilization(utilizations, transformed[core].(string))
+		assert.True(t, ok)
+		actual[transformed[kubernetesPod].(string)] = value
+	}
+	assert.Equal(t, map[string]float64{"pod-a": 30, "pod-b": 90}, actual)
+}
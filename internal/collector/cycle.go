@@ -11,6 +11,8 @@ import (
 const (
 	taskExecutionCycle = "task_execution_cycle"
 	totalCycleCount    = "total_cycle_count"
+	coreBusyRatio      = "coreBusyRatio"
+	deviceBusyRatio    = "deviceBusyRatio"
 )
 
 type cycleCollector struct {
@@ -20,6 +22,10 @@ type cycleCollector struct {
 
 	taskExecutionCycleCounterVec *prometheus.CounterVec
 	totalCycleCountCounterVec    *prometheus.CounterVec
+	coreBusyRatioGaugeVec        *prometheus.GaugeVec
+	busyRatioGaugeVec            *prometheus.GaugeVec
+
+	busyRatioTracker *busyRatioTracker
 }
 
 var _ Collector = (*cycleCollector)(nil)
@@ -29,6 +35,8 @@ func NewCycleCollector(devices []smi.Device, metricFactory MetricFactory, kubeRe
 		devices:       devices,
 		metricFactory: metricFactory,
 		kubeResMapper: kubeResMapper,
+
+		busyRatioTracker: newBusyRatioTracker(),
 	}
 }
 
@@ -38,7 +46,7 @@ func (t *cycleCollector) Register() {
 		Help: "The current task execution cycle of NPU device",
 	}
 
-	t.taskExecutionCycleCounterVec = prometheus.NewCounterVec(taskExecutionCycleOpts, defaultMetricLabels())
+	t.taskExecutionCycleCounterVec = prometheus.NewCounterVec(taskExecutionCycleOpts, defaultMetricLabels(t.kubeResMapper.MetricLabels()...))
 
 	prometheus.MustRegister(NewLabelFilterCollector(
 		t.taskExecutionCycleCounterVec,
@@ -51,12 +59,36 @@ func (t *cycleCollector) Register() {
 		Help: "The current total cycle count of NPU device",
 	}
 
-	t.totalCycleCountCounterVec = prometheus.NewCounterVec(totalCycleCountOpts, defaultMetricLabels())
+	t.totalCycleCountCounterVec = prometheus.NewCounterVec(totalCycleCountOpts, defaultMetricLabels(t.kubeResMapper.MetricLabels()...))
 	prometheus.MustRegister(NewLabelFilterCollector(
 		t.totalCycleCountCounterVec,
 		prometheus.Opts(totalCycleCountOpts),
 		prometheus.CounterValue,
 	))
+
+	coreBusyRatioOpts := prometheus.GaugeOpts{
+		Name: "furiosa_npu_core_busy_ratio",
+		Help: "The ratio of task execution cycles to total cycles of NPU device core over the last interval",
+	}
+
+	t.coreBusyRatioGaugeVec = prometheus.NewGaugeVec(coreBusyRatioOpts, defaultMetricLabels(t.kubeResMapper.MetricLabels()...))
+	prometheus.MustRegister(NewLabelFilterCollector(
+		t.coreBusyRatioGaugeVec,
+		prometheus.Opts(coreBusyRatioOpts),
+		prometheus.GaugeValue,
+	))
+
+	busyRatioOpts := prometheus.GaugeOpts{
+		Name: "furiosa_npu_busy_ratio",
+		Help: "The ratio of task execution cycles to total cycles of NPU device over the last interval",
+	}
+
+	t.busyRatioGaugeVec = prometheus.NewGaugeVec(busyRatioOpts, defaultMetricLabels(t.kubeResMapper.MetricLabels()...))
+	prometheus.MustRegister(NewLabelFilterCollector(
+		t.busyRatioGaugeVec,
+		prometheus.Opts(busyRatioOpts),
+		prometheus.GaugeValue,
+	))
 }
 
 func (t *cycleCollector) Collect() error {
@@ -77,15 +109,33 @@ func (t *cycleCollector) Collect() error {
 		}
 
 		counters := perfCounters.PerformanceCounter()
+		samples := make(map[uint32]cycleSample, len(counters))
+		for _, counter := range counters {
+			samples[counter.Core()] = cycleSample{
+				taskExecutionCycle: counter.TaskExecutionCycle(),
+				totalCycleCount:    counter.CycleCount(),
+			}
+		}
+
+		coreRatios, deviceRatio, deviceRatioFound := t.busyRatioTracker.update(metric[uuid].(string), samples)
+
 		for _, counter := range counters {
 			coreIndex := counter.Core()
 			duplicated := deepCopyMetric(metric)
 			duplicated[core] = strconv.Itoa(int(coreIndex))
 			duplicated[taskExecutionCycle] = float64(counter.TaskExecutionCycle())
 			duplicated[totalCycleCount] = float64(counter.CycleCount())
+			if ratio, ok := coreRatios[coreIndex]; ok {
+				duplicated[coreBusyRatio] = ratio
+			}
 
 			metricContainer = append(metricContainer, duplicated)
 		}
+
+		if deviceRatioFound {
+			metric[deviceBusyRatio] = deviceRatio
+			metricContainer = append(metricContainer, metric)
+		}
 	}
 
 	if err := t.postProcess(metricContainer); err != nil {
@@ -100,43 +150,34 @@ func (t *cycleCollector) Collect() error {
 }
 
 func (t *cycleCollector) postProcess(metrics MetricContainer) error {
+	labelNames := defaultMetricLabels(t.kubeResMapper.MetricLabels()...)
 	t.taskExecutionCycleCounterVec.Reset()
 	t.totalCycleCountCounterVec.Reset()
+	t.coreBusyRatioGaugeVec.Reset()
+	t.busyRatioGaugeVec.Reset()
+
+	// the device wise busy ratio covers the cores of every pod sharing the device, so it is not attributed to pods
+	coreWiseMetrics := make(MetricContainer, 0, len(metrics))
+	for _, metric := range metrics {
+		if value, ok := metric[deviceBusyRatio]; ok {
+			t.busyRatioGaugeVec.With(metricLabels(labelNames, metric, nil)).Set(value.(float64))
+		} else {
+			coreWiseMetrics = append(coreWiseMetrics, metric)
+		}
+	}
 
-	transformed := t.kubeResMapper.TransformDeviceMetrics(metrics, true)
+	transformed := t.kubeResMapper.TransformDeviceMetrics(coreWiseMetrics, true)
 	for _, metric := range transformed {
 		if value, ok := metric[taskExecutionCycle]; ok {
-			t.taskExecutionCycleCounterVec.With(prometheus.Labels{
-				arch:                metric[arch].(string),
-				core:                metric[core].(string),
-				device:              metric[device].(string),
-				uuid:                metric[uuid].(string),
-				bdf:                 metric[bdf].(string),
-				firmwareVersion:     metric[firmwareVersion].(string),
-				pertVersion:         metric[pertVersion].(string),
-				driverVersion:       metric[driverVersion].(string),
-				hostname:            metric[hostname].(string),
-				kubernetesNamespace: metric[kubernetesNamespace].(string),
-				kubernetesPod:       metric[kubernetesPod].(string),
-				kubernetesContainer: metric[kubernetesContainer].(string),
-			}).Add(value.(float64))
+			t.taskExecutionCycleCounterVec.With(metricLabels(labelNames, metric, nil)).Add(value.(float64))
 		}
 
 		if value, ok := metric[totalCycleCount]; ok {
-			t.totalCycleCountCounterVec.With(prometheus.Labels{
-				arch:                metric[arch].(string),
-				core:                metric[core].(string),
-				device:              metric[device].(string),
-				uuid:                metric[uuid].(string),
-				bdf:                 metric[bdf].(string),
-				firmwareVersion:     metric[firmwareVersion].(string),
-				pertVersion:         metric[pertVersion].(string),
-				driverVersion:       metric[driverVersion].(string),
-				hostname:            metric[hostname].(string),
-				kubernetesNamespace: metric[kubernetesNamespace].(string),
-				kubernetesPod:       metric[kubernetesPod].(string),
-				kubernetesContainer: metric[kubernetesContainer].(string),
-			}).Add(value.(float64))
+			t.totalCycleCountCounterVec.With(metricLabels(labelNames, metric, nil)).Add(value.(float64))
+		}
+
+		if value, ok := metric[coreBusyRatio]; ok {
+			t.coreBusyRatioGaugeVec.With(metricLabels(labelNames, metric, nil)).Set(value.(float64))
 		}
 	}
 
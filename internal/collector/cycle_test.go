@@ -64,18 +64,62 @@ furiosa_npu_total_cycle_count{arch="rngd",core="4",device="npu0",pci_bus_id="bdf
 furiosa_npu_total_cycle_count{arch="rngd",core="5",device="npu0",pci_bus_id="bdf",uuid="uuid"} 5678
 furiosa_npu_total_cycle_count{arch="rngd",core="6",device="npu0",pci_bus_id="bdf",uuid="uuid"} 5678
 furiosa_npu_total_cycle_count{arch="rngd",core="7",device="npu0",pci_bus_id="bdf",uuid="uuid"} 5678
+`,
+		},
+		{
+			description: "cycle metrics with busy ratio",
+			source: func() MetricContainer {
+				mc := MetricContainer{}
+				for i := 0; i < 2; i++ {
+					metric := newMetric()
+					metric[arch] = "rngd"
+					metric[core] = strconv.Itoa(i)
+					metric[device] = "npu0"
+					metric[uuid] = "uuid"
+					metric[bdf] = "bdf"
+					metric[taskExecutionCycle] = float64(1234)
+					metric[totalCycleCount] = float64(5678)
+					metric[coreBusyRatio] = float64(i) * 0.5
+
+					mc = append(mc, metric)
+				}
+
+				metric := newMetric()
+				metric[arch] = "rngd"
+				metric[core] = "0-7"
+				metric[device] = "npu0"
+				metric[uuid] = "uuid"
+				metric[bdf] = "bdf"
+				metric[deviceBusyRatio] = float64(0.25)
+				mc = append(mc, metric)
+
+				return mc
+			}(),
+			expected: `
+# HELP furiosa_npu_task_execution_cycle The current task execution cycle of NPU device
+# TYPE furiosa_npu_task_execution_cycle counter
+furiosa_npu_task_execution_cycle{arch="rngd",core="0",device="npu0",pci_bus_id="bdf",uuid="uuid"} 1234
+furiosa_npu_task_execution_cycle{arch="rngd",core="1",device="npu0",pci_bus_id="bdf",uuid="uuid"} 1234
+# HELP furiosa_npu_core_busy_ratio The ratio of task execution cycles to total cycles of NPU device core over the last interval
+# TYPE furiosa_npu_core_busy_ratio gauge
+furiosa_npu_core_busy_ratio{arch="rngd",core="0",device="npu0",pci_bus_id="bdf",uuid="uuid"} 0
+furiosa_npu_core_busy_ratio{arch="rngd",core="1",device="npu0",pci_bus_id="bdf",uuid="uuid"} 0.5
+# HELP furiosa_npu_busy_ratio The ratio of task execution cycles to total cycles of NPU device over the last interval
+# TYPE furiosa_npu_busy_ratio gauge
+furiosa_npu_busy_ratio{arch="rngd",core="0-7",device="npu0",pci_bus_id="bdf",uuid="uuid"} 0.25
 `,
 		},
 	}
 
 	collector := newFakeCycleCollector()
+	useTestRegistry(t)
 	collector.Register()
 	for _, tc := range tests {
 		t.Run(tc.description, func(t *testing.T) {
 			err := collector.postProcess(tc.source)
 			assert.Nil(t, err)
 
-			err = testutil.GatherAndCompare(prometheus.DefaultGatherer, strings.NewReader(head+tc.expected), "furiosa_npu_task_execution_cycle")
+			err = testutil.GatherAndCompare(prometheus.DefaultGatherer, strings.NewReader(head+tc.expected), "furiosa_npu_task_execution_cycle", "furiosa_npu_core_busy_ratio", "furiosa_npu_busy_ratio")
 			assert.NoError(t, err)
 		})
 	}
@@ -0,0 +1,144 @@
+package collector
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/furiosa-ai/furiosa-smi-go/pkg/smi"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type deviceInfoCollector struct {
+	devices       []smi.Device
+	metricFactory MetricFactory
+	kubeResMapper KubeResourcesMapper
+
+	deviceInfoGaugeVec     *prometheus.GaugeVec
+	deviceFileInfoGaugeVec *prometheus.GaugeVec
+}
+
+var _ Collector = (*deviceInfoCollector)(nil)
+
+func NewDeviceInfoCollector(devices []smi.Device, metricFactory MetricFactory, kubeResMapper KubeResourcesMapper) Collector {
+	return &deviceInfoCollector{
+		devices:       devices,
+		metricFactory: metricFactory,
+		kubeResMapper: kubeResMapper,
+	}
+}
+
+func (t *deviceInfoCollector) Register() {
+	deviceInfoOpts := prometheus.GaugeOpts{
+		Name: "furiosa_npu_device_info",
+		Help: "The identity information of NPU device. The value is always 1",
+	}
+
+	t.deviceInfoGaugeVec = prometheus.NewGaugeVec(deviceInfoOpts, append(defaultMetricLabels(t.kubeResMapper.MetricLabels()...), deviceIndex, serial, numaNode, coreNum, major, minor))
+
+	prometheus.MustRegister(NewLabelFilterCollector(
+		t.deviceInfoGaugeVec,
+		prometheus.Opts(deviceInfoOpts),
+		prometheus.GaugeValue,
+	))
+
+	deviceFileInfoOpts := prometheus.GaugeOpts{
+		Name: "furiosa_npu_device_file_info",
+		Help: "The device file information of NPU device. The value is always 1",
+	}
+
+	// the device files are static inventory, so they are neither attributed to pods nor labeled by core
+	t.deviceFileInfoGaugeVec = prometheus.NewGaugeVec(deviceFileInfoOpts, []string{arch, device, uuid, filePath, fileCores})
+
+	prometheus.MustRegister(NewLabelFilterCollector(
+		t.deviceFileInfoGaugeVec,
+		prometheus.Opts(deviceFileInfoOpts),
+		prometheus.GaugeValue,
+	))
+}
+
+func (t *deviceInfoCollector) Collect() error {
+	metricContainer := make(MetricContainer, 0, len(t.devices))
+
+	errs := make([]error, 0)
+	for _, d := range t.devices {
+		metric, err := t.metricFactory.NewDeviceWiseMetric(d)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		info, err := getDeviceInfo(d)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		infoMetric := deepCopyMetric(metric)
+		infoMetric[deviceIndex] = strconv.Itoa(int(info.index))
+		infoMetric[serial] = info.serial
+		infoMetric[numaNode] = strconv.Itoa(int(info.numaNode))
+		infoMetric[coreNum] = strconv.Itoa(int(info.coreNum))
+		infoMetric[major] = strconv.Itoa(int(info.major))
+		infoMetric[minor] = strconv.Itoa(int(info.minor))
+		metricContainer = append(metricContainer, infoMetric)
+
+		for _, file := range info.deviceFiles {
+			fileMetric := deepCopyMetric(metric)
+			fileMetric[filePath] = file.path
+			fileMetric[fileCores] = file.coreLabel
+			metricContainer = append(metricContainer, fileMetric)
+		}
+	}
+
+	if err := t.postProcess(metricContainer); err != nil {
+		errs = append(errs, err)
+	}
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	return nil
+}
+
+func (t *deviceInfoCollector) postProcess(metrics MetricContainer) error {
+	labelNames := defaultMetricLabels(t.kubeResMapper.MetricLabels()...)
+	infoMetrics := make(MetricContainer, 0, len(metrics))
+	fileMetrics := make(MetricContainer, 0, len(metrics))
+	for _, metric := range metrics {
+		if _, ok := metric[filePath]; ok {
+			fileMetrics = append(fileMetrics, metric)
+		} else {
+			infoMetrics = append(infoMetrics, metric)
+		}
+	}
+
+	transformed := t.kubeResMapper.TransformDeviceMetrics(infoMetrics, false)
+	t.deviceInfoGaugeVec.Reset()
+	t.deviceFileInfoGaugeVec.Reset()
+
+	for _, metric := range transformed {
+		if value, ok := metric[serial]; ok {
+			t.deviceInfoGaugeVec.With(metricLabels(labelNames, metric, prometheus.Labels{
+				deviceIndex: metric[deviceIndex].(string),
+				serial:      value.(string),
+				numaNode:    metric[numaNode].(string),
+				coreNum:     metric[coreNum].(string),
+				major:       metric[major].(string),
+				minor:       metric[minor].(string),
+			})).Set(1)
+		}
+	}
+
+	for _, metric := range fileMetrics {
+		t.deviceFileInfoGaugeVec.With(prometheus.Labels{
+			arch:      metric[arch].(string),
+			device:    metric[device].(string),
+			uuid:      metric[uuid].(string),
+			filePath:  metric[filePath].(string),
+			fileCores: metric[fileCores].(string),
+		}).Set(1)
+	}
+
+	return nil
+}
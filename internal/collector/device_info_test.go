@@ -0,0 +1,55 @@
+package collector
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/furiosa-ai/furiosa-smi-go/pkg/smi"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestDeviceInfoCollector_Collect(t *testing.T) {
+	devices := []smi.Device{smi.GetStaticMockDevice(smi.ArchRngd, 4)}
+
+	collector := NewDeviceInfoCollector(devices, NewMetricFactory("node", "1.0.0", false), NewFakeKubeResourcesMapper())
+	useTestRegistry(t)
+	collector.Register()
+
+	err := collector.Collect()
+	assert.NoError(t, err)
+
+	expected := `
+# HELP furiosa_npu_device_info The identity information of NPU device. The value is always 1
+# TYPE furiosa_npu_device_info gauge
+furiosa_npu_device_info{arch="rngd",core="0-7",core_num="8",device="npu4",driver_version="1.0.0",firmware_version="1.6.0+c1bebfd",hostname="node",index="4",major="238",minor="0",numa_node="1",pci_bus_id="0000:9e:00.0",pert_version="0.0.0+",serial="TEST0236FH505KRE4",uuid="A76AAD68-6855-40B1-9E86-D080852D1C84"} 1
+`
+	err = testutil.GatherAndCompare(prometheus.DefaultGatherer, strings.NewReader(expected), "furiosa_npu_device_info")
+	assert.NoError(t, err)
+
+	families, err := prometheus.DefaultGatherer.Gather()
+	assert.NoError(t, err)
+
+	fileCoreLabels := map[string]string{}
+	for _, family := range families {
+		if family.GetName() != "furiosa_npu_device_file_info" {
+			continue
+		}
+
+		for _, m := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, pair := range m.GetLabel() {
+				labels[pair.GetName()] = pair.GetValue()
+			}
+			fileCoreLabels[labels[filePath]] = labels[fileCores]
+			assert.NotContains(t, labels, core, "the device files are covered by the cores label only")
+		}
+	}
+
+	assert.Len(t, fileCoreLabels, 14)
+	assert.Equal(t, "0", fileCoreLabels["/dev/rngd/npu4pe0"])
+	assert.Equal(t, "0-3", fileCoreLabels["/dev/rngd/npu4pe0-3"])
+	assert.Equal(t, "4-7", fileCoreLabels["/dev/rngd/npu4pe4-7"])
+}
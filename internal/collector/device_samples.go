@@ -0,0 +1,93 @@
+package collector
+
+import (
+	"maps"
+	"sync"
+)
+
+// DeviceSamples keeps the last power and core utilization read from each device by the power and core utilization
+// collectors, so that the pod aggregate collector builds on the same readings without reading the devices again.
+// A nil DeviceSamples records nothing.
+type DeviceSamples struct {
+	sync.Mutex
+
+	// power maps uuid to the last power reading and the energy accumulated by the power collector.
+	power map[string]powerSample
+	// utilizations maps uuid to core index to the last core utilization.
+	utilizations map[string]map[int]float64
+}
+
+type powerSample struct {
+	watts  float64
+	joules float64
+}
+
+func NewDeviceSamples() *DeviceSamples {
+	return &DeviceSamples{
+		power:        make(map[string]powerSample),
+		utilizations: make(map[string]map[int]float64),
+	}
+}
+
+// setPower records the power of the device and its total energy.
+func (s *DeviceSamples) setPower(uuid string, sample powerSample) {
+	if s == nil {
+		return
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	s.power[uuid] = sample
+}
+
+// forgetPower forgets the power of the device, which cannot be read.
+func (s *DeviceSamples) forgetPower(uuid string) {
+	if s == nil {
+		return
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	delete(s.power, uuid)
+}
+
+func (s *DeviceSamples) lastPower(uuid string) (powerSample, bool) {
+	s.Lock()
+	defer s.Unlock()
+
+	sample, found := s.power[uuid]
+	return sample, found
+}
+
+// setUtilizations records the utilization of each core of the device.
+func (s *DeviceSamples) setUtilizations(uuid string, utilizations map[int]float64) {
+	if s == nil {
+		return
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	s.utilizations[uuid] = utilizations
+}
+
+// forgetUtilizations forgets the core utilizations of the device, which cannot be read.
+func (s *DeviceSamples) forgetUtilizations(uuid string) {
+	if s == nil {
+		return
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	delete(s.utilizations, uuid)
+}
+
+func (s *DeviceSamples) lastUtilizations(uuid string) map[int]float64 {
+	s.Lock()
+	defer s.Unlock()
+
+	return maps.Clone(s.utilizations[uuid])
+}
@@ -0,0 +1,101 @@
+package collector
+
+import (
+	"sync"
+	"time"
+)
+
+// energyShareGracePeriod is how long the accumulated energy of a series that is no longer attributed is kept, so that
+// the counter is not reset when the attribution is missing for a few syncs, e.g. while the kubelet restarts. It is
+// the default of the pod information cache TTL.
+const energyShareGracePeriod = time.Minute
+
+type energySample struct {
+	timestamp time.Time
+	watts     float64
+	joules    float64
+}
+
+// energyIntegrator integrates power readings over real elapsed time into accumulated energy. The integration happens
+// on every collection, so the accumulated energy does not depend on how often the metrics are scraped.
+type energyIntegrator struct {
+	sync.Mutex
+	now func() time.Time
+
+	// devices maps uuid to the last power sample and the accumulated energy of the device.
+	devices map[string]energySample
+	// shares maps the label set of an attributed series to the energy accumulated by the series.
+	shares map[string]energyShare
+}
+
+type energyShare struct {
+	joules float64
+	seenAt time.Time
+}
+
+func newEnergyIntegrator(now func() time.Time) *energyIntegrator {
+	return &energyIntegrator{
+		now:     now,
+		devices: make(map[string]energySample),
+		shares:  make(map[string]energyShare),
+	}
+}
+
+// integrate adds the energy consumed since the previous reading of the device using the trapezoidal rule, and returns
+// the energy of this interval and the total accumulated energy in joules.
+func (e *energyIntegrator) integrate(uuid string, watts float64) (float64, float64) {
+	e.Lock()
+	defer e.Unlock()
+
+	now := e.now()
+	previous, found := e.devices[uuid]
+	if !found {
+		e.devices[uuid] = energySample{timestamp: now, watts: watts}
+		return 0, 0
+	}
+
+	var delta float64
+	if elapsed := now.Sub(previous.timestamp).Seconds(); elapsed > 0 {
+		delta = (previous.watts + watts) / 2 * elapsed
+	}
+
+	total := previous.joules + delta
+	e.devices[uuid] = energySample{timestamp: now, watts: watts, joules: total}
+
+	return delta, total
+}
+
+// accumulateShares adds the given energy to each attributed series, and returns the accumulated energy of each of
+// them. The energy of the series that are no longer attributed is kept for energyShareGracePeriod, and resumed if they
+// are attributed again in the meantime.
+func (e *energyIntegrator) accumulateShares(deltas map[string]float64) map[string]float64 {
+	e.Lock()
+	defer e.Unlock()
+
+	now := e.now()
+	for key, share := range e.shares {
+		if _, found := deltas[key]; !found && now.Sub(share.seenAt) > energyShareGracePeriod {
+			delete(e.shares, key)
+		}
+	}
+
+	shares := make(map[string]float64, len(deltas))
+	for key, delta := range deltas {
+		joules := e.shares[key].joules + delta
+		e.shares[key] = energyShare{joules: joules, seenAt: now}
+		shares[key] = joules
+	}
+
+	return shares
+}
+
+// coreShare returns the fraction of the device cores denoted by coreLabel among the cores denoted by deviceCoreLabel.
+func coreShare(coreLabel, deviceCoreLabel string) float64 {
+	allocated := coreCount(coreLabel)
+	total := coreCount(deviceCoreLabel)
+	if allocated == 0 || total == 0 || allocated > total {
+		return 1
+	}
+
+	return float64(allocated) / float64(total)
+}
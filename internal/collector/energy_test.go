@@ -0,0 +1,173 @@
+package collector
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/furiosa-ai/furiosa-smi-go/pkg/smi"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (f *fakeClock) Now() time.Time {
+	return f.now
+}
+
+func (f *fakeClock) Advance(d time.Duration) {
+	f.now = f.now.Add(d)
+}
+
+func TestEnergyIntegrator_Integrate(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	integrator := newEnergyIntegrator(clock.Now)
+
+	delta, total := integrator.integrate("uuid", 100)
+	assert.Equal(t, float64(0), delta)
+	assert.Equal(t, float64(0), total)
+
+	clock.Advance(10 * time.Second)
+	delta, total = integrator.integrate("uuid", 100)
+	assert.Equal(t, float64(1000), delta)
+	assert.Equal(t, float64(1000), total)
+
+	// a gap between collections is integrated over the real elapsed time
+	clock.Advance(60 * time.Second)
+	delta, total = integrator.integrate("uuid", 200)
+	assert.Equal(t, float64(9000), delta)
+	assert.Equal(t, float64(10000), total)
+
+	// the clock going backwards does not decrease the energy
+	clock.Advance(-time.Second)
+	delta, total = integrator.integrate("uuid", 200)
+	assert.Equal(t, float64(0), delta)
+	assert.Equal(t, float64(10000), total)
+}
+
+func TestEnergyIntegrator_AccumulateShares(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	integrator := newEnergyIntegrator(clock.Now)
+
+	assert.Equal(t, map[string]float64{"a": 100, "b": 100}, integrator.accumulateShares(map[string]float64{"a": 100, "b": 100}))
+
+	// b is not attributed for a sync, and resumes from its accumulated energy
+	clock.Advance(10 * time.Second)
+	assert.Equal(t, map[string]float64{"a": 200}, integrator.accumulateShares(map[string]float64{"a": 100}))
+
+	clock.Advance(10 * time.Second)
+	assert.Equal(t, map[string]float64{"a": 300, "b": 200}, integrator.accumulateShares(map[string]float64{"a": 100, "b": 100}))
+
+	// b is dropped once it is not attributed for longer than the grace period
+	clock.Advance(energyShareGracePeriod + time.Second)
+	assert.Equal(t, map[string]float64{"a": 400}, integrator.accumulateShares(map[string]float64{"a": 100}))
+
+	clock.Advance(10 * time.Second)
+	assert.Equal(t, map[string]float64{"a": 500, "b": 100}, integrator.accumulateShares(map[string]float64{"a": 100, "b": 100}))
+}
+
+func TestCoreShare(t *testing.T) {
+	assert.Equal(t, float64(1), coreShare("0-7", "0-7"))
+	assert.Equal(t, 0.5, coreShare("4-7", "0-7"))
+	assert.Equal(t, 0.125, coreShare("3", "0-7"))
+	assert.Equal(t, float64(1), coreShare("invalid", "0-7"))
+}
+
+func newFakeEnergyPowerCollector(devices []smi.Device, kubeResMapper KubeResourcesMapper, clock *fakeClock) *powerCollector {
+	return &powerCollector{
+		devices:          devices,
+		metricFactory:    NewMetricFactory("", "", false),
+		kubeResMapper:    kubeResMapper,
+		gaugeVec:         prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "furiosa_npu_hw_power"}, append(defaultMetricLabels(), label)),
+		energyCounterVec: prometheus.NewCounterVec(prometheus.CounterOpts{Name: "furiosa_npu_energy_joules_total"}, defaultMetricLabels()),
+		headroomGaugeVec: prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "furiosa_npu_power_headroom_watts"}, defaultMetricLabels()),
+		energyIntegrator: newEnergyIntegrator(clock.Now),
+	}
+}
+
+func TestPowerCollector_Energy(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	devices := []smi.Device{smi.GetStaticMockDevice(smi.ArchRngd, 0)}
+	deviceUUID := "A76AAD68-6855-40B1-9E86-D080852D1C80"
+
+	kubeResMapper := &kubeResourcesMapper{
+		enabled:         true,
+		deviceWiseCache: deviceWiseCache{},
+		coreWiseCache:   coreWiseCache{},
+	}
+
+	collector := newFakeEnergyPowerCollector(devices, kubeResMapper, clock)
+
+	// the mock device consumes 100W, and is not attributed to any pod for the first 10 seconds
+	assert.NoError(t, collector.Collect())
+	clock.Advance(10 * time.Second)
+	assert.NoError(t, collector.Collect())
+
+	// then the device is partitioned into two pods for the next 10 seconds
+	kubeResMapper.deviceWiseCache[deviceUUID] = []podInfo{
+		{Name: "pod-a", Namespace: "default", ContainerName: "main", AllocatedPE: []int{0, 1, 2, 3}, CoreLabel: "0-3"},
+		{Name: "pod-b", Namespace: "default", ContainerName: "main", AllocatedPE: []int{4, 5, 6, 7}, CoreLabel: "4-7"},
+	}
+	clock.Advance(10 * time.Second)
+	assert.NoError(t, collector.Collect())
+
+	expected := `
+# HELP furiosa_npu_energy_joules_total 
+# TYPE furiosa_npu_energy_joules_total counter
+furiosa_npu_energy_joules_total{arch="rngd",core="0-7",device="npu0",firmware_version="1.6.0+c1bebfd",pci_bus_id="0000:27:00.0",pert_version="0.0.0+",uuid="A76AAD68-6855-40B1-9E86-D080852D1C80"} 2000
+furiosa_npu_energy_joules_total{arch="rngd",container="main",core="0-3",device="npu0",firmware_version="1.6.0+c1bebfd",namespace="default",pci_bus_id="0000:27:00.0",pert_version="0.0.0+",pod="pod-a",uuid="A76AAD68-6855-40B1-9E86-D080852D1C80"} 500
+furiosa_npu_energy_joules_total{arch="rngd",container="main",core="4-7",device="npu0",firmware_version="1.6.0+c1bebfd",namespace="default",pci_bus_id="0000:27:00.0",pert_version="0.0.0+",pod="pod-b",uuid="A76AAD68-6855-40B1-9E86-D080852D1C80"} 500
+`
+	err := testutil.CollectAndCompare(NewLabelFilterCollector(collector.energyCounterVec, prometheus.Opts{Name: "furiosa_npu_energy_joules_total"}, prometheus.CounterValue), strings.NewReader(expected), "furiosa_npu_energy_joules_total")
+	assert.NoError(t, err)
+
+	// pod-b is gone and pod-a keeps accumulating its share
+	kubeResMapper.deviceWiseCache[deviceUUID] = kubeResMapper.deviceWiseCache[deviceUUID][:1]
+	clock.Advance(10 * time.Second)
+	assert.NoError(t, collector.Collect())
+
+	expected = `
+# HELP furiosa_npu_energy_joules_total 
+# TYPE furiosa_npu_energy_joules_total counter
+furiosa_npu_energy_joules_total{arch="rngd",core="0-7",device="npu0",firmware_version="1.6.0+c1bebfd",pci_bus_id="0000:27:00.0",pert_version="0.0.0+",uuid="A76AAD68-6855-40B1-9E86-D080852D1C80"} 3000
+furiosa_npu_energy_joules_total{arch="rngd",container="main",core="0-3",device="npu0",firmware_version="1.6.0+c1bebfd",namespace="default",pci_bus_id="0000:27:00.0",pert_version="0.0.0+",pod="pod-a",uuid="A76AAD68-6855-40B1-9E86-D080852D1C80"} 1000
+`
+	err = testutil.CollectAndCompare(NewLabelFilterCollector(collector.energyCounterVec, prometheus.Opts{Name: "furiosa_npu_energy_joules_total"}, prometheus.CounterValue), strings.NewReader(expected), "furiosa_npu_energy_joules_total")
+	assert.NoError(t, err)
+}
+
+func TestPowerCollector_EnergyWithoutPodName(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	devices := []smi.Device{smi.GetStaticMockDevice(smi.ArchRngd, 0)}
+
+	// the checkpoint fallback only knows the pod uid and the container name
+	kubeResMapper := &kubeResourcesMapper{
+		enabled: true,
+		deviceWiseCache: deviceWiseCache{
+			"A76AAD68-6855-40B1-9E86-D080852D1C80": {
+				{UID: "uid-a", ContainerName: "main", AllocatedPE: []int{0, 1, 2, 3}, CoreLabel: "0-3"},
+				{UID: "uid-b", ContainerName: "main", AllocatedPE: []int{4, 5, 6, 7}, CoreLabel: "4-7"},
+			},
+		},
+		coreWiseCache: coreWiseCache{},
+	}
+
+	collector := newFakeEnergyPowerCollector(devices, kubeResMapper, clock)
+	assert.NoError(t, collector.Collect())
+	clock.Advance(10 * time.Second)
+	assert.NoError(t, collector.Collect())
+
+	expected := `
+# HELP furiosa_npu_energy_joules_total 
+# TYPE furiosa_npu_energy_joules_total counter
+furiosa_npu_energy_joules_total{arch="rngd",core="0-7",device="npu0",firmware_version="1.6.0+c1bebfd",pci_bus_id="0000:27:00.0",pert_version="0.0.0+",uuid="A76AAD68-6855-40B1-9E86-D080852D1C80"} 1000
+furiosa_npu_energy_joules_total{arch="rngd",container="main",core="0-3",device="npu0",firmware_version="1.6.0+c1bebfd",pci_bus_id="0000:27:00.0",pert_version="0.0.0+",pod_uid="uid-a",uuid="A76AAD68-6855-40B1-9E86-D080852D1C80"} 500
+furiosa_npu_energy_joules_total{arch="rngd",container="main",core="4-7",device="npu0",firmware_version="1.6.0+c1bebfd",pci_bus_id="0000:27:00.0",pert_version="0.0.0+",pod_uid="uid-b",uuid="A76AAD68-6855-40B1-9E86-D080852D1C80"} 500
+`
+	err := testutil.CollectAndCompare(NewLabelFilterCollector(collector.energyCounterVec, prometheus.Opts{Name: "furiosa_npu_energy_joules_total"}, prometheus.CounterValue), strings.NewReader(expected), "furiosa_npu_energy_joules_total")
+	assert.NoError(t, err)
+}
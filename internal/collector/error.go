@@ -0,0 +1,188 @@
+package collector
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/furiosa-ai/furiosa-smi-go/pkg/smi"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	errorResetSuffix = "_resets"
+)
+
+// errorAttributes lists the error counters exposed by the driver under `<sysfs root>/class/npu_mgmt/<device>_mgmt/`.
+var errorAttributes = []string{
+	"axi_discard_error",
+	"axi_doorbell_done",
+	"axi_fetch_error",
+	"axi_post_error",
+	"device_error",
+	"pcie_discard_error",
+	"pcie_doorbell_done",
+	"pcie_fetch_error",
+	"pcie_post_error",
+}
+
+type errorCollector struct {
+	devices       []smi.Device
+	metricFactory MetricFactory
+	kubeResMapper KubeResourcesMapper
+	sysfsRoot     string
+
+	errorCounterVec      *prometheus.CounterVec
+	errorResetCounterVec *prometheus.CounterVec
+
+	// previous keeps the last read value of each error counter by device uuid and attribute to detect counter resets.
+	previous map[string]map[string]uint64
+	// resets keeps the number of counter resets by device uuid and attribute.
+	resets map[string]map[string]uint64
+}
+
+var _ Collector = (*errorCollector)(nil)
+
+func NewErrorCollector(devices []smi.Device, metricFactory MetricFactory, kubeResMapper KubeResourcesMapper, sysfsRoot string) Collector {
+	return &errorCollector{
+		devices:       devices,
+		metricFactory: metricFactory,
+		kubeResMapper: kubeResMapper,
+		sysfsRoot:     sysfsRoot,
+		previous:      make(map[string]map[string]uint64),
+		resets:        make(map[string]map[string]uint64),
+	}
+}
+
+func (t *errorCollector) Register() {
+	errorOpts := prometheus.CounterOpts{
+		Name: "furiosa_npu_error",
+		Help: "The error count of NPU device",
+	}
+
+	t.errorCounterVec = prometheus.NewCounterVec(errorOpts, append(defaultMetricLabels(t.kubeResMapper.MetricLabels()...), label))
+
+	prometheus.MustRegister(NewLabelFilterCollector(
+		t.errorCounterVec,
+		prometheus.Opts(errorOpts),
+		prometheus.CounterValue,
+	))
+
+	errorResetOpts := prometheus.CounterOpts{
+		Name: "furiosa_npu_error_counter_resets_total",
+		Help: "The number of error counter resets of NPU device, e.g. by driver reloads",
+	}
+
+	t.errorResetCounterVec = prometheus.NewCounterVec(errorResetOpts, append(defaultMetricLabels(t.kubeResMapper.MetricLabels()...), label))
+
+	prometheus.MustRegister(NewLabelFilterCollector(
+		t.errorResetCounterVec,
+		prometheus.Opts(errorResetOpts),
+		prometheus.CounterValue,
+	))
+}
+
+func (t *errorCollector) Collect() error {
+	metricContainer := make(MetricContainer, 0, len(t.devices))
+
+	errs := make([]error, 0)
+	for _, d := range t.devices {
+		metric, err := t.metricFactory.NewDeviceWiseMetric(d)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		uuidValue := metric[uuid].(string)
+		counters, err := readErrorCounters(t.sysfsRoot, metric[device].(string))
+		if err != nil {
+			errs = append(errs, err)
+		}
+
+		if len(counters) == 0 {
+			continue
+		}
+
+		if _, ok := t.previous[uuidValue]; !ok {
+			t.previous[uuidValue] = make(map[string]uint64)
+			t.resets[uuidValue] = make(map[string]uint64)
+		}
+
+		for attribute, value := range counters {
+			if previous, found := t.previous[uuidValue][attribute]; found && value < previous {
+				t.resets[uuidValue][attribute]++
+			}
+			t.previous[uuidValue][attribute] = value
+
+			metric[attribute] = float64(value)
+			metric[attribute+errorResetSuffix] = float64(t.resets[uuidValue][attribute])
+		}
+
+		metricContainer = append(metricContainer, metric)
+	}
+
+	if err := t.postProcess(metricContainer); err != nil {
+		errs = append(errs, err)
+	}
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	return nil
+}
+
+func (t *errorCollector) postProcess(metrics MetricContainer) error {
+	labelNames := defaultMetricLabels(t.kubeResMapper.MetricLabels()...)
+	transformed := t.kubeResMapper.TransformDeviceMetrics(metrics, false)
+	t.errorCounterVec.Reset()
+	t.errorResetCounterVec.Reset()
+
+	for _, metric := range transformed {
+		for _, attribute := range errorAttributes {
+			if value, ok := metric[attribute]; ok {
+				t.errorCounterVec.With(metricLabels(labelNames, metric, prometheus.Labels{label: attribute})).Add(value.(float64))
+			}
+
+			if value, ok := metric[attribute+errorResetSuffix]; ok {
+				t.errorResetCounterVec.With(metricLabels(labelNames, metric, prometheus.Labels{label: attribute})).Add(value.(float64))
+			}
+		}
+	}
+
+	return nil
+}
+
+// readErrorCounters reads the error counters of the given device from sysfs. Missing attributes are skipped.
+func readErrorCounters(sysfsRoot string, deviceName string) (map[string]uint64, error) {
+	dir := filepath.Join(sysfsRoot, "class", "npu_mgmt", deviceName+"_mgmt")
+
+	counters := make(map[string]uint64, len(errorAttributes))
+	errs := make([]error, 0)
+	for _, attribute := range errorAttributes {
+		raw, err := os.ReadFile(filepath.Join(dir, attribute))
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		value, err := strconv.ParseUint(strings.TrimSpace(string(raw)), 0, 64)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to parse '%s' of %s: %w", attribute, deviceName, err))
+			continue
+		}
+
+		counters[attribute] = value
+	}
+
+	if len(errs) > 0 {
+		return counters, errors.Join(errs...)
+	}
+
+	return counters, nil
+}
@@ -0,0 +1,83 @@
+package collector
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/furiosa-ai/furiosa-smi-go/pkg/smi"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func writeFakeErrorCounters(t *testing.T, sysfsRoot string, deviceName string, counters map[string]string) {
+	dir := filepath.Join(sysfsRoot, "class", "npu_mgmt", deviceName+"_mgmt")
+	assert.NoError(t, os.MkdirAll(dir, 0755))
+
+	for attribute, value := range counters {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, attribute), []byte(value+"\n"), 0644))
+	}
+}
+
+func TestErrorCollector_Collect(t *testing.T) {
+	sysfsRoot := t.TempDir()
+	devices := []smi.Device{smi.GetStaticMockDevice(smi.ArchRngd, 0)}
+
+	collector := NewErrorCollector(devices, NewMetricFactory("", "", false), NewFakeKubeResourcesMapper(), sysfsRoot)
+	useTestRegistry(t)
+	collector.Register()
+
+	// first collection
+	writeFakeErrorCounters(t, sysfsRoot, "npu0", map[string]string{
+		"axi_fetch_error":  "3",
+		"device_error":     "0x10",
+		"pcie_post_error":  "7",
+		"pcie_fetch_error": "1",
+	})
+	err := collector.Collect()
+	assert.NoError(t, err)
+
+	// second collection after driver reload, which resets `pcie_post_error`
+	writeFakeErrorCounters(t, sysfsRoot, "npu0", map[string]string{
+		"pcie_post_error": "2",
+	})
+	err = collector.Collect()
+	assert.NoError(t, err)
+
+	expected := `
+# HELP furiosa_npu_error The error count of NPU device
+# TYPE furiosa_npu_error counter
+furiosa_npu_error{arch="rngd",core="0-7",device="npu0",firmware_version="1.6.0+c1bebfd",label="axi_fetch_error",pci_bus_id="0000:27:00.0",pert_version="0.0.0+",uuid="A76AAD68-6855-40B1-9E86-D080852D1C80"} 3
+furiosa_npu_error{arch="rngd",core="0-7",device="npu0",firmware_version="1.6.0+c1bebfd",label="device_error",pci_bus_id="0000:27:00.0",pert_version="0.0.0+",uuid="A76AAD68-6855-40B1-9E86-D080852D1C80"} 16
+furiosa_npu_error{arch="rngd",core="0-7",device="npu0",firmware_version="1.6.0+c1bebfd",label="pcie_fetch_error",pci_bus_id="0000:27:00.0",pert_version="0.0.0+",uuid="A76AAD68-6855-40B1-9E86-D080852D1C80"} 1
+furiosa_npu_error{arch="rngd",core="0-7",device="npu0",firmware_version="1.6.0+c1bebfd",label="pcie_post_error",pci_bus_id="0000:27:00.0",pert_version="0.0.0+",uuid="A76AAD68-6855-40B1-9E86-D080852D1C80"} 2
+# HELP furiosa_npu_error_counter_resets_total The number of error counter resets of NPU device, e.g. by driver reloads
+# TYPE furiosa_npu_error_counter_resets_total counter
+furiosa_npu_error_counter_resets_total{arch="rngd",core="0-7",device="npu0",firmware_version="1.6.0+c1bebfd",label="axi_fetch_error",pci_bus_id="0000:27:00.0",pert_version="0.0.0+",uuid="A76AAD68-6855-40B1-9E86-D080852D1C80"} 0
+furiosa_npu_error_counter_resets_total{arch="rngd",core="0-7",device="npu0",firmware_version="1.6.0+c1bebfd",label="device_error",pci_bus_id="0000:27:00.0",pert_version="0.0.0+",uuid="A76AAD68-6855-40B1-9E86-D080852D1C80"} 0
+furiosa_npu_error_counter_resets_total{arch="rngd",core="0-7",device="npu0",firmware_version="1.6.0+c1bebfd",label="pcie_fetch_error",pci_bus_id="0000:27:00.0",pert_version="0.0.0+",uuid="A76AAD68-6855-40B1-9E86-D080852D1C80"} 0
+furiosa_npu_error_counter_resets_total{arch="rngd",core="0-7",device="npu0",firmware_version="1.6.0+c1bebfd",label="pcie_post_error",pci_bus_id="0000:27:00.0",pert_version="0.0.0+",uuid="A76AAD68-6855-40B1-9E86-D080852D1C80"} 1
+`
+	err = testutil.GatherAndCompare(prometheus.DefaultGatherer, strings.NewReader(expected), "furiosa_npu_error", "furiosa_npu_error_counter_resets_total")
+	assert.NoError(t, err)
+}
+
+func TestReadErrorCounters(t *testing.T) {
+	sysfsRoot := t.TempDir()
+
+	counters, err := readErrorCounters(sysfsRoot, "npu0")
+	assert.NoError(t, err)
+	assert.Empty(t, counters)
+
+	writeFakeErrorCounters(t, sysfsRoot, "npu0", map[string]string{
+		"axi_post_error": "5",
+		"device_error":   "invalid",
+	})
+
+	counters, err = readErrorCounters(sysfsRoot, "npu0")
+	assert.Error(t, err)
+	assert.Equal(t, map[string]uint64{"axi_post_error": 5}, counters)
+}
@@ -11,3 +11,7 @@ func NewFakeKubeResourcesMapper() KubeResourcesMapper {
 func (k *fakeKubeResourcesMapper) TransformDeviceMetrics(metrics MetricContainer, _ bool) MetricContainer {
 	return metrics
 }
+
+func (k *fakeKubeResourcesMapper) MetricLabels() []string {
+	return nil
+}
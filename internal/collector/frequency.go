@@ -35,7 +35,7 @@ func (t *coreFrequencyCollector) Register() {
 		Help: "The current core frequency of NPU device (MHz)",
 	}
 
-	t.gaugeVec = prometheus.NewGaugeVec(opts, defaultMetricLabels())
+	t.gaugeVec = prometheus.NewGaugeVec(opts, defaultMetricLabels(t.kubeResMapper.MetricLabels()...))
 
 	prometheus.MustRegister(NewLabelFilterCollector(
 		t.gaugeVec,
@@ -82,25 +82,13 @@ func (t *coreFrequencyCollector) Collect() error {
 }
 
 func (t *coreFrequencyCollector) postProcess(metrics MetricContainer) error {
+	labelNames := defaultMetricLabels(t.kubeResMapper.MetricLabels()...)
 	transformed := t.kubeResMapper.TransformDeviceMetrics(metrics, true)
 	t.gaugeVec.Reset()
 
 	for _, metric := range transformed {
 		if value, ok := metric[peFrequency]; ok {
-			t.gaugeVec.With(prometheus.Labels{
-				arch:                metric[arch].(string),
-				core:                metric[core].(string),
-				device:              metric[device].(string),
-				uuid:                metric[uuid].(string),
-				bdf:                 metric[bdf].(string),
-				firmwareVersion:     metric[firmwareVersion].(string),
-				pertVersion:         metric[pertVersion].(string),
-				driverVersion:       metric[driverVersion].(string),
-				hostname:            metric[hostname].(string),
-				kubernetesNamespace: metric[kubernetesNamespace].(string),
-				kubernetesPod:       metric[kubernetesPod].(string),
-				kubernetesContainer: metric[kubernetesContainer].(string),
-			}).Set(float64(value.(uint32)))
+			t.gaugeVec.With(metricLabels(labelNames, metric, nil)).Set(float64(value.(uint32)))
 		}
 	}
 
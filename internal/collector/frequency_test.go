@@ -57,6 +57,7 @@ furiosa_npu_core_frequency{arch="rngd",core="7",device="npu0",pci_bus_id="bdf",u
 	}
 
 	collector := newFakeCoreFrequencyCollector()
+	useTestRegistry(t)
 	collector.Register()
 	for _, tc := range tests {
 		t.Run(tc.description, func(t *testing.T) {
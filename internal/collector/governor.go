@@ -0,0 +1,90 @@
+package collector
+
+import (
+	"errors"
+
+	"github.com/furiosa-ai/furiosa-smi-go/pkg/smi"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	governorProfile = "governorProfile"
+)
+
+type governorProfileCollector struct {
+	devices       []smi.Device
+	metricFactory MetricFactory
+	gaugeVec      *prometheus.GaugeVec
+	kubeResMapper KubeResourcesMapper
+}
+
+var _ Collector = (*governorProfileCollector)(nil)
+
+func NewGovernorProfileCollector(devices []smi.Device, metricFactory MetricFactory, kubeResMapper KubeResourcesMapper) Collector {
+	return &governorProfileCollector{
+		devices:       devices,
+		metricFactory: metricFactory,
+		kubeResMapper: kubeResMapper,
+	}
+}
+
+func (t *governorProfileCollector) Register() {
+	opts := prometheus.GaugeOpts{
+		Name: "furiosa_npu_governor_profile",
+		Help: "The current power governor profile of NPU device",
+	}
+
+	t.gaugeVec = prometheus.NewGaugeVec(opts, append(defaultMetricLabels(t.kubeResMapper.MetricLabels()...), profile))
+
+	prometheus.MustRegister(NewLabelFilterCollector(
+		t.gaugeVec,
+		prometheus.Opts(opts),
+		prometheus.GaugeValue,
+	))
+}
+
+func (t *governorProfileCollector) Collect() error {
+	metricContainer := make(MetricContainer, 0, len(t.devices))
+
+	errs := make([]error, 0)
+	for _, d := range t.devices {
+		metric, err := t.metricFactory.NewDeviceWiseMetric(d)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		value, err := d.GovernorProfile()
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		metric[governorProfile] = value.String()
+		metricContainer = append(metricContainer, metric)
+	}
+
+	if err := t.postProcess(metricContainer); err != nil {
+		errs = append(errs, err)
+	}
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	return nil
+}
+
+func (t *governorProfileCollector) postProcess(metrics MetricContainer) error {
+	labelNames := defaultMetricLabels(t.kubeResMapper.MetricLabels()...)
+	transformed := t.kubeResMapper.TransformDeviceMetrics(metrics, false)
+	t.gaugeVec.Reset()
+
+	for _, metric := range transformed {
+		if value, ok := metric[governorProfile]; ok {
+			t.gaugeVec.With(metricLabels(labelNames, metric, prometheus.Labels{profile: value.(string)})).Set(1)
+		}
+	}
+
+	return nil
+}
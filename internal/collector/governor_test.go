@@ -0,0 +1,84 @@
+package collector
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/furiosa-ai/furiosa-smi-go/pkg/smi"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func newFakeGovernorProfileCollector() Collector {
+	return &governorProfileCollector{
+		devices:       nil,
+		metricFactory: nil,
+		kubeResMapper: NewFakeKubeResourcesMapper(),
+	}
+}
+
+func TestGovernorProfileCollector_PostProcessing(t *testing.T) {
+	tests := []struct {
+		description string
+		source      MetricContainer
+		expected    string
+	}{
+		{
+			description: "devices with different governor profiles",
+			source: func() MetricContainer {
+				tc := MetricContainer{}
+				profiles := []smi.GovernorProfile{smi.GovernorProfileOnDemand, smi.GovernorProfilePowerSave}
+				for i, p := range profiles {
+					metric := newMetric()
+					metric[arch] = "rngd"
+					metric[core] = "0-7"
+					metric[device] = []string{"npu0", "npu1"}[i]
+					metric[uuid] = []string{"uuid0", "uuid1"}[i]
+					metric[governorProfile] = p.String()
+					tc = append(tc, metric)
+				}
+				return tc
+			}(),
+			expected: `
+# HELP furiosa_npu_governor_profile The current power governor profile of NPU device
+# TYPE furiosa_npu_governor_profile gauge
+furiosa_npu_governor_profile{arch="rngd",core="0-7",device="npu0",profile="OnDemand",uuid="uuid0"} 1
+furiosa_npu_governor_profile{arch="rngd",core="0-7",device="npu1",profile="PowerSave",uuid="uuid1"} 1
+`,
+		},
+	}
+
+	collector := newFakeGovernorProfileCollector()
+	useTestRegistry(t)
+	collector.Register()
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			err := collector.postProcess(tc.source)
+			assert.NoError(t, err)
+
+			err = testutil.GatherAndCompare(prometheus.DefaultGatherer, strings.NewReader(head+tc.expected), "furiosa_npu_governor_profile")
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestGovernorProfileCollector_Collect(t *testing.T) {
+	devices := []smi.Device{smi.GetStaticMockDevice(smi.ArchRngd, 0)}
+
+	collector := NewGovernorProfileCollector(devices, NewMetricFactory("", "", false), NewFakeKubeResourcesMapper())
+	useTestRegistry(t)
+	collector.Register()
+
+	err := collector.Collect()
+	assert.NoError(t, err)
+
+	expected := `
+# HELP furiosa_npu_governor_profile The current power governor profile of NPU device
+# TYPE furiosa_npu_governor_profile gauge
+furiosa_npu_governor_profile{arch="rngd",core="0-7",device="npu0",firmware_version="1.6.0+c1bebfd",pci_bus_id="0000:27:00.0",pert_version="0.0.0+",profile="OnDemand",uuid="A76AAD68-6855-40B1-9E86-D080852D1C80"} 1
+`
+	err = testutil.GatherAndCompare(prometheus.DefaultGatherer, strings.NewReader(expected), "furiosa_npu_governor_profile")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,85 @@
+package collector
+
+import (
+	"errors"
+
+	"github.com/furiosa-ai/furiosa-smi-go/pkg/smi"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// infoCollector exports a single `furiosa_npu_info` series per device carrying the version, BDF and hostname labels,
+// which can be joined with the other metrics on `uuid` when the info metric labeling mode is enabled.
+type infoCollector struct {
+	devices       []smi.Device
+	metricFactory MetricFactory
+	gaugeVec      *prometheus.GaugeVec
+}
+
+var _ Collector = (*infoCollector)(nil)
+
+func NewInfoCollector(devices []smi.Device, metricFactory MetricFactory) Collector {
+	return &infoCollector{
+		devices:       devices,
+		metricFactory: metricFactory,
+	}
+}
+
+func (t *infoCollector) Register() {
+	opts := prometheus.GaugeOpts{
+		Name: "furiosa_npu_info",
+		Help: "The version, pci bus id and hostname information of NPU device. The value is always 1",
+	}
+
+	t.gaugeVec = prometheus.NewGaugeVec(opts, []string{arch, device, uuid, bdf, firmwareVersion, pertVersion, driverVersion, hostname})
+
+	prometheus.MustRegister(NewLabelFilterCollector(
+		t.gaugeVec,
+		prometheus.Opts(opts),
+		prometheus.GaugeValue,
+	))
+}
+
+func (t *infoCollector) Collect() error {
+	metricContainer := make(MetricContainer, 0, len(t.devices))
+
+	errs := make([]error, 0)
+	for _, d := range t.devices {
+		metric, err := t.metricFactory.NewInfoMetric(d)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		metricContainer = append(metricContainer, metric)
+	}
+
+	if err := t.postProcess(metricContainer); err != nil {
+		errs = append(errs, err)
+	}
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	return nil
+}
+
+func (t *infoCollector) postProcess(metrics MetricContainer) error {
+	t.gaugeVec.Reset()
+
+	// the info metric is not transformed by the kube resources mapper to keep a single series per device
+	for _, metric := range metrics {
+		t.gaugeVec.With(prometheus.Labels{
+			arch:            metric[arch].(string),
+			device:          metric[device].(string),
+			uuid:            metric[uuid].(string),
+			bdf:             metric[bdf].(string),
+			firmwareVersion: metric[firmwareVersion].(string),
+			pertVersion:     metric[pertVersion].(string),
+			driverVersion:   metric[driverVersion].(string),
+			hostname:        metric[hostname].(string),
+		}).Set(1)
+	}
+
+	return nil
+}
@@ -0,0 +1,51 @@
+package collector
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/furiosa-ai/furiosa-smi-go/pkg/smi"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestInfoCollector_Collect(t *testing.T) {
+	devices := []smi.Device{smi.GetStaticMockDevice(smi.ArchRngd, 0)}
+	metricFactory := NewMetricFactory("node", "1.0.0", true)
+
+	collector := NewInfoCollector(devices, metricFactory)
+	useTestRegistry(t)
+	collector.Register()
+
+	err := collector.Collect()
+	assert.NoError(t, err)
+
+	expected := `
+# HELP furiosa_npu_info The version, pci bus id and hostname information of NPU device. The value is always 1
+# TYPE furiosa_npu_info gauge
+furiosa_npu_info{arch="rngd",device="npu0",driver_version="1.0.0",firmware_version="1.6.0+c1bebfd",hostname="node",pci_bus_id="0000:27:00.0",pert_version="0.0.0+",uuid="A76AAD68-6855-40B1-9E86-D080852D1C80"} 1
+`
+	err = testutil.GatherAndCompare(prometheus.DefaultGatherer, strings.NewReader(expected), "furiosa_npu_info")
+	assert.NoError(t, err)
+}
+
+func TestMetricFactory_InfoMetricLabels(t *testing.T) {
+	d := smi.GetStaticMockDevice(smi.ArchRngd, 0)
+
+	metric, err := NewMetricFactory("node", "1.0.0", false).NewDeviceWiseMetric(d)
+	assert.NoError(t, err)
+	assert.Equal(t, "0000:27:00.0", metric[bdf])
+	assert.Equal(t, "1.0.0", metric[driverVersion])
+	assert.Equal(t, "node", metric[hostname])
+
+	metric, err = NewMetricFactory("node", "1.0.0", true).NewDeviceWiseMetric(d)
+	assert.NoError(t, err)
+	assert.Equal(t, "A76AAD68-6855-40B1-9E86-D080852D1C80", metric[uuid])
+	assert.Equal(t, "", metric[bdf])
+	assert.Equal(t, "", metric[firmwareVersion])
+	assert.Equal(t, "", metric[pertVersion])
+	assert.Equal(t, "", metric[driverVersion])
+	assert.Equal(t, "", metric[hostname])
+}
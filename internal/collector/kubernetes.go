@@ -2,23 +2,30 @@ package collector
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"net"
+	"maps"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/furiosa-ai/furiosa-smi-go/pkg/smi"
+	"github.com/rs/zerolog"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/backoff"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
-	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/status"
 
-	podResourcesAPI "k8s.io/kubelet/pkg/apis/podresources/v1alpha1"
+	podResourcesAPI "k8s.io/kubelet/pkg/apis/podresources/v1"
+	podResourcesV1Alpha1API "k8s.io/kubelet/pkg/apis/podresources/v1alpha1"
 )
 
 const (
-	k8sSocket                         = "/var/lib/kubelet/pod-resources/kubelet.sock"
 	furiosaResourcePrefix             = "furiosa.ai"
 	furiosaPartitionedResourcePattern = "_cores_"
 )
@@ -27,8 +34,19 @@ type podInfo struct {
 	Name          string
 	Namespace     string
 	ContainerName string
-	AllocatedPE   []int
-	CoreLabel     string
+	UID           string
+	ContainerID   string
+	Topology      string
+	// ClaimName and ClaimNamespace are set if the device is allocated through a DRA ResourceClaim.
+	ClaimName      string
+	ClaimNamespace string
+	// OwnerKind and OwnerName are the top level workload owning the pod, such as a Deployment.
+	OwnerKind string
+	OwnerName string
+	// Metadata maps the metric labels of the allowlisted pod labels and annotations to their values.
+	Metadata    map[string]string
+	AllocatedPE []int
+	CoreLabel   string
 }
 
 // deviceWiseCache maps uuid to pod information for device wise metrics
@@ -39,33 +57,202 @@ type coreToPodInfo map[int]podInfo
 // coreWiseCache maps uuid to core to pod name for core wise metrics
 type coreWiseCache map[string]coreToPodInfo
 
+// allocatableCache maps uuid to the cores the kubelet considers allocatable
+type allocatableCache map[string]map[int]bool
+
+const (
+	allocationStateAllocated   = "allocated"
+	allocationStateAllocatable = "allocatable"
+	allocationStateUnavailable = "unavailable"
+)
+
+// AttributionMode decides how the device-wise metrics of the devices allocated to pods are attributed to the pods.
+type AttributionMode string
+
+const (
+	// AttributionModeDuplicate keeps the device series of a partitioned device and adds a copy per pod, and replaces
+	// the device series of an exclusively allocated device.
+	AttributionModeDuplicate AttributionMode = "duplicate"
+	// AttributionModeReplace replaces the device series of an allocated device by a copy per pod.
+	AttributionModeReplace AttributionMode = "replace"
+	// AttributionModeSplit replaces the device series like AttributionModeReplace, and divides the values summed over
+	// the cores of the device by the core share of the pod.
+	AttributionModeSplit AttributionMode = "split"
+	// AttributionModeInfo keeps the series without the pod labels, and leaves the attribution to the allocation info
+	// metric.
+	AttributionModeInfo AttributionMode = "info"
+)
+
+// splitValues are the device-wise values summed over the cores of the device, which are divided by the core share of
+// the pod in the split attribution mode. The energy is not included as the power collector already accumulates the
+// energy of the attributed series from the core share.
+var splitValues = []string{rms}
+
+// errKubeletSocketMissing is returned while the kubelet pod resources socket does not exist, e.g. on the nodes where
+// the pod resources API is disabled.
+var errKubeletSocketMissing = errors.New("kubelet socket does not exist")
+
+// ParseAttributionMode returns the attribution mode of the given name, or AttributionModeDuplicate if empty.
+func ParseAttributionMode(name string) (AttributionMode, error) {
+	switch mode := AttributionMode(name); mode {
+	case "":
+		return AttributionModeDuplicate, nil
+	case AttributionModeDuplicate, AttributionModeReplace, AttributionModeSplit, AttributionModeInfo:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown attribution mode '%s', must be one of duplicate, replace, split and info", name)
+	}
+}
+
 type KubeResourcesMapper interface {
 	TransformDeviceMetrics(metrics MetricContainer, coreWiseMetric bool) MetricContainer
+	// MetricLabels returns the labels added by the mapper to the default metric labels.
+	MetricLabels() []string
+}
+
+// AllocationStateMapper is a KubeResourcesMapper which also knows the pods allocated to each device, and whether the
+// kubelet considers each core allocatable.
+type AllocationStateMapper interface {
+	KubeResourcesMapper
+	allocationState(uuid string, core int) (string, bool)
+	// allocatedPods returns the pods allocated to the device, with the allocated cores.
+	allocatedPods(uuid string) []podInfo
+}
+
+// KubeResourcesMapperOptions configures the kube resources mapper.
+type KubeResourcesMapperOptions struct {
+	// Enabled enables the kubernetes resources labels.
+	Enabled bool
+	// KubeletSocket is the path of the kubelet pod resources socket.
+	KubeletSocket string
+	// CacheTTL is how long the last pod information is kept when the kubelet cannot be reached.
+	CacheTTL time.Duration
+	// CgroupFallback enables attributing the devices unknown to the kubelet to the pod uid and container id read from
+	// the cgroup of the processes holding them, found in ProcRoot.
+	CgroupFallback bool
+	ProcRoot       string
+	// CheckpointFallback enables attributing the devices to the pod uid and container name recorded in the kubelet
+	// device manager checkpoint KubeletCheckpoint, while the kubelet pod resources API is unavailable.
+	CheckpointFallback bool
+	KubeletCheckpoint  string
+	// PodMetadata configures the pod informer enriching the attributed metrics with the pod metadata.
+	PodMetadata PodMetadataOptions
+	// AttributionMode decides how the device-wise metrics are attributed, AttributionModeDuplicate if empty.
+	AttributionMode AttributionMode
+}
+
+// deviceCores maps uuid to the sorted cores of the device.
+type deviceCores map[string][]int
+
+// kubeletCache keeps the pod information built from the last successful kubelet response.
+type kubeletCache struct {
+	deviceWise  deviceWiseCache
+	coreWise    coreWiseCache
+	allocatable allocatableCache
+	syncedAt    time.Time
 }
 
 type kubeResourcesMapper struct {
-	enabled bool
+	enabled        bool
+	logger         zerolog.Logger
+	client         *podResourcesClient
+	cacheTTL       time.Duration
+	now            func() time.Time
+	deviceCores    deviceCores
+	lastKubelet    kubeletCache
+	cgroupFallback *cgroupAttributor
+	checkpoint     *checkpointAttributor
+	podMetadata    *podMetadataInformer
+	// kubeletSocketMissing is whether the missing kubelet socket has been reported since the last kubelet response.
+	kubeletSocketMissing bool
+	// metricLabels are the labels of the allowlisted pod metadata added by the pod informer.
+	metricLabels []string
+	// attributionMode is the duplicate mode if empty.
+	attributionMode AttributionMode
 	sync.RWMutex
 	deviceWiseCache
 	coreWiseCache
+	allocatableCache
 }
 
-var _ KubeResourcesMapper = (*kubeResourcesMapper)(nil)
+var _ AllocationStateMapper = (*kubeResourcesMapper)(nil)
 
-func NewKubeResourcesMapper(ctx context.Context, enabled bool) (KubeResourcesMapper, chan<- struct{}, error) {
+// NewKubeResourcesMapper creates a mapper attributing the metrics to the pods using the kubelet pod resources API,
+// refreshed whenever the returned channel is triggered.
+func NewKubeResourcesMapper(ctx context.Context, logger zerolog.Logger, options KubeResourcesMapperOptions, devices []smi.Device) (KubeResourcesMapper, chan<- struct{}, error) {
 	syncChan := make(chan struct{}, 1)
 
+	// the cores are only used to parse the device ids of the kubelet and its checkpoint, which are never read while
+	// the mapper is disabled
+	var cores deviceCores
+	if options.Enabled {
+		var err error
+		if cores, err = buildDeviceCores(devices); err != nil {
+			return nil, nil, err
+		}
+	}
+
 	mapper := &kubeResourcesMapper{
-		enabled:         enabled,
+		enabled:         options.Enabled,
+		logger:          logger,
+		cacheTTL:        options.CacheTTL,
+		now:             time.Now,
+		deviceCores:     cores,
+		attributionMode: options.AttributionMode,
 		deviceWiseCache: make(deviceWiseCache),
 	}
 
+	if options.Enabled {
+		client, err := newPodResourcesClient(options.KubeletSocket)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		mapper.client = client
+	}
+
+	if options.CgroupFallback {
+		attributor, err := newCgroupAttributor(options.ProcRoot, devices)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		mapper.cgroupFallback = attributor
+	}
+
+	if options.CheckpointFallback {
+		mapper.checkpoint = newCheckpointAttributor(options.KubeletCheckpoint, cores)
+	}
+
+	if options.PodMetadata.Enabled {
+		client, err := newKubernetesClient(options.PodMetadata.KubeConfig)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		informer, err := newPodMetadataInformer(client, options.PodMetadata)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// the metadata labels must be known before the collectors are registered
+		mapper.metricLabels = informer.labelNames(options.PodMetadata)
+
+		if err := informer.start(ctx); err != nil {
+			return nil, nil, err
+		}
+		mapper.podMetadata = informer
+	}
+
 	go func() {
 		for {
 			select {
 			case <-syncChan:
 				mapper.syncPodInfoCache()
 			case <-ctx.Done():
+				if mapper.client != nil {
+					mapper.client.close()
+				}
 				return
 			}
 		}
@@ -79,10 +266,80 @@ func (k *kubeResourcesMapper) syncPodInfoCache() {
 		return
 	}
 
-	deviceWise, coreWise, err := buildMultiWiseCache()
-	if err != nil {
-		fmt.Printf("failed to get kubernetes pod information cache: %v", err)
-		return
+	var deviceWise deviceWiseCache
+	var coreWise coreWiseCache
+	var allocatable allocatableCache
+
+	if kubelet, err := k.client.buildMultiWiseCache(k.logger, k.deviceCores); err != nil {
+		if !errors.Is(err, errKubeletSocketMissing) {
+			k.logger.Warn().Err(err).Msg("failed to get kubernetes pod information cache")
+		} else if !k.kubeletSocketMissing {
+			// the socket stays missing on the nodes without the pod resources API, so it is only reported once
+			k.logger.Warn().Err(err).Msg("failed to get kubernetes pod information cache")
+			k.kubeletSocketMissing = true
+		}
+
+		// the pods of the checkpoint are only known by uid, and named by the pod informer if enabled
+		var checkpointDeviceWise deviceWiseCache
+		var checkpointCoreWise coreWiseCache
+		var checkpointModTime time.Time
+		if k.checkpoint != nil {
+			checkpointDeviceWise, checkpointCoreWise, checkpointModTime, err = k.checkpoint.buildMultiWiseCache()
+			if err != nil {
+				k.logger.Warn().Err(err).Msg("failed to get kubelet checkpoint pod information cache")
+			}
+		}
+
+		switch {
+		case checkpointDeviceWise != nil && checkpointModTime.After(k.lastKubelet.syncedAt):
+			// the checkpoint written since the last kubelet response is fresher than the kept pod information
+			deviceWise = maps.Clone(checkpointDeviceWise)
+			coreWise = maps.Clone(checkpointCoreWise)
+		case !k.lastKubelet.syncedAt.IsZero() && k.now().Sub(k.lastKubelet.syncedAt) <= k.cacheTTL:
+			// keep the last pod information for a bounded ttl, so that a kubelet restart does not drop the pod labels
+			deviceWise = maps.Clone(k.lastKubelet.deviceWise)
+			coreWise = maps.Clone(k.lastKubelet.coreWise)
+			allocatable = k.lastKubelet.allocatable
+		case checkpointDeviceWise != nil:
+			deviceWise = maps.Clone(checkpointDeviceWise)
+			coreWise = maps.Clone(checkpointCoreWise)
+		case k.cgroupFallback == nil && k.checkpoint == nil:
+			k.logger.Warn().Msg("kubernetes pod information cache expired")
+		}
+	} else {
+		kubelet.syncedAt = k.now()
+		k.lastKubelet = kubelet
+		k.kubeletSocketMissing = false
+
+		deviceWise = maps.Clone(kubelet.deviceWise)
+		coreWise = maps.Clone(kubelet.coreWise)
+		allocatable = kubelet.allocatable
+	}
+
+	if deviceWise == nil {
+		deviceWise = make(deviceWiseCache)
+		coreWise = make(coreWiseCache)
+	}
+
+	if k.cgroupFallback != nil {
+		fallbackDeviceWise, fallbackCoreWise, err := k.cgroupFallback.buildMultiWiseCache()
+		if err != nil {
+			k.logger.Warn().Err(err).Msg("failed to get cgroup pod information cache")
+		}
+
+		// only the devices unknown to the kubelet are attributed by the fallback
+		for uuidValue, podInfoSlice := range fallbackDeviceWise {
+			if _, found := deviceWise[uuidValue]; found {
+				continue
+			}
+
+			deviceWise[uuidValue] = podInfoSlice
+			coreWise[uuidValue] = fallbackCoreWise[uuidValue]
+		}
+	}
+
+	if k.podMetadata != nil {
+		k.podMetadata.enrichCache(deviceWise, coreWise)
 	}
 
 	k.Lock()
@@ -90,10 +347,47 @@ func (k *kubeResourcesMapper) syncPodInfoCache() {
 
 	k.deviceWiseCache = deviceWise
 	k.coreWiseCache = coreWise
+	k.allocatableCache = allocatable
 }
 
-func (k *kubeResourcesMapper) TransformDeviceMetrics(metrics MetricContainer, coreWiseMetric bool) MetricContainer {
+// allocationState returns whether the core is allocated to a pod, allocatable, or unavailable according to the
+// kubelet. It returns false if the allocatable resources are unknown, e.g. on old kubelets.
+func (k *kubeResourcesMapper) allocationState(uuid string, core int) (string, bool) {
+	k.RLock()
+	defer k.RUnlock()
+
+	if !k.enabled || k.allocatableCache == nil {
+		return "", false
+	}
+
+	if _, found := k.coreWiseCache[uuid][core]; found {
+		return allocationStateAllocated, true
+	}
+
+	if k.allocatableCache[uuid][core] {
+		return allocationStateAllocatable, true
+	}
+
+	return allocationStateUnavailable, true
+}
+
+func (k *kubeResourcesMapper) allocatedPods(uuid string) []podInfo {
+	k.RLock()
+	defer k.RUnlock()
+
 	if !k.enabled {
+		return nil
+	}
+
+	return slices.Clone(k.deviceWiseCache[uuid])
+}
+
+func (k *kubeResourcesMapper) MetricLabels() []string {
+	return k.metricLabels
+}
+
+func (k *kubeResourcesMapper) TransformDeviceMetrics(metrics MetricContainer, coreWiseMetric bool) MetricContainer {
+	if !k.enabled || k.attributionMode == AttributionModeInfo {
 		return metrics
 	}
 
@@ -129,11 +423,7 @@ func (k *kubeResourcesMapper) TransformDeviceMetrics(metrics MetricContainer, co
 				continue
 			}
 
-			copied := deepCopyMetric(metric)
-			copied[kubernetesNamespace] = podInformation.Namespace
-			copied[kubernetesPod] = podInformation.Name
-			copied[kubernetesContainer] = podInformation.ContainerName
-			transformed = append(transformed, copied)
+			transformed = append(transformed, withPodLabels(metric, podInformation, false))
 
 		} else {
 			// handle device wise metrics
@@ -143,25 +433,25 @@ func (k *kubeResourcesMapper) TransformDeviceMetrics(metrics MetricContainer, co
 				continue
 			}
 
-			if len(podInfoSlice) == 1 && len(podInfoSlice[0].AllocatedPE) == 8 {
-				// exclusive allocation case
-				copied := deepCopyMetric(metric)
-				copied[kubernetesNamespace] = podInfoSlice[0].Namespace
-				copied[kubernetesPod] = podInfoSlice[0].Name
-				copied[kubernetesContainer] = podInfoSlice[0].ContainerName
-				copied[core] = podInfoSlice[0].CoreLabel
-				transformed = append(transformed, copied)
-			} else {
-				// partitioned allocation case, preserve origin metric and duplicate the metric for each pod
-				transformed = append(transformed, metric)
+			switch k.attributionMode {
+			case AttributionModeReplace:
 				for _, podInformation := range podInfoSlice {
-					duplicated := deepCopyMetric(metric)
-					duplicated[kubernetesNamespace] = podInformation.Namespace
-					duplicated[kubernetesPod] = podInformation.Name
-					duplicated[kubernetesContainer] = podInformation.ContainerName
-					duplicated[core] = podInformation.CoreLabel
-
-					transformed = append(transformed, duplicated)
+					transformed = append(transformed, withPodLabels(metric, podInformation, true))
+				}
+			case AttributionModeSplit:
+				for _, podInformation := range podInfoSlice {
+					transformed = append(transformed, k.withSplitValues(withPodLabels(metric, podInformation, true), uuidValue, podInformation))
+				}
+			default:
+				if len(podInfoSlice) == 1 && len(podInfoSlice[0].AllocatedPE) == len(k.deviceCores[uuidValue]) {
+					// exclusive allocation case
+					transformed = append(transformed, withPodLabels(metric, podInfoSlice[0], true))
+				} else {
+					// partitioned allocation case, preserve origin metric and duplicate the metric for each pod
+					transformed = append(transformed, metric)
+					for _, podInformation := range podInfoSlice {
+						transformed = append(transformed, withPodLabels(metric, podInformation, true))
+					}
 				}
 			}
 		}
@@ -170,28 +460,140 @@ func (k *kubeResourcesMapper) TransformDeviceMetrics(metrics MetricContainer, co
 	return transformed
 }
 
-func buildMultiWiseCache() (deviceWiseCache, coreWiseCache, error) {
-	deviceWise := make(deviceWiseCache)
-	coreWise := make(coreWiseCache)
+// withSplitValues divides the split values of the attributed metric by the fraction of the device cores allocated to
+// the pod.
+func (k *kubeResourcesMapper) withSplitValues(metric Metric, uuidValue string, podInformation podInfo) Metric {
+	total := len(k.deviceCores[uuidValue])
+	if total == 0 || len(podInformation.AllocatedPE) >= total {
+		return metric
+	}
 
-	_, err := os.Stat(k8sSocket)
+	share := float64(len(podInformation.AllocatedPE)) / float64(total)
+	for _, key := range splitValues {
+		if value, ok := metric[key].(float64); ok {
+			metric[key] = value * share
+		}
+	}
+
+	return metric
+}
+
+func withPodLabels(metric Metric, podInformation podInfo, overrideCore bool) Metric {
+	copied := deepCopyMetric(metric)
+	copied[kubernetesNamespace] = podInformation.Namespace
+	copied[kubernetesPod] = podInformation.Name
+	copied[kubernetesContainer] = podInformation.ContainerName
+	copied[kubernetesPodUID] = podInformation.UID
+	copied[containerID] = podInformation.ContainerID
+	copied[kubernetesTopology] = podInformation.Topology
+	copied[claimName] = podInformation.ClaimName
+	copied[claimNamespace] = podInformation.ClaimNamespace
+	copied[ownerKind] = podInformation.OwnerKind
+	copied[ownerName] = podInformation.OwnerName
+	for name, value := range podInformation.Metadata {
+		copied[name] = value
+	}
+	if overrideCore {
+		copied[core] = podInformation.CoreLabel
+	}
+
+	return copied
+}
+
+// podResourcesClient keeps a long-lived connection to the kubelet pod resources API, which is re-established with
+// exponential backoff when the kubelet restarts.
+type podResourcesClient struct {
+	socket string
+	conn   *grpc.ClientConn
+}
+
+func newPodResourcesClient(socket string) (*podResourcesClient, error) {
+	conn, err := grpc.NewClient("unix://"+socket,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithConnectParams(grpc.ConnectParams{
+			Backoff: backoff.Config{
+				BaseDelay:  time.Second,
+				Multiplier: 1.6,
+				Jitter:     0.2,
+				MaxDelay:   2 * time.Minute,
+			},
+			MinConnectTimeout: 10 * time.Second,
+		}),
+		// the kubelet rejects pings more frequent than every 5 minutes
+		grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:    5 * time.Minute,
+			Timeout: 20 * time.Second,
+		}))
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to '%s'; err: %w", socket, err)
+	}
+
+	return &podResourcesClient{socket: socket, conn: conn}, nil
+}
+
+func (p *podResourcesClient) close() {
+	_ = p.conn.Close()
+}
+
+func (p *podResourcesClient) buildMultiWiseCache(logger zerolog.Logger, cores deviceCores) (kubeletCache, error) {
+	_, err := os.Stat(p.socket)
 	if os.IsNotExist(err) {
-		return nil, nil, fmt.Errorf("kubelet socket '%s' does not exist", k8sSocket)
+		return kubeletCache{}, fmt.Errorf("%w: '%s'", errKubeletSocketMissing, p.socket)
 	}
 
-	c, cleanup, err := connectToServer()
+	devicePods, err := listPods(p.conn)
 
 	if err != nil {
-		return nil, nil, err
+		return kubeletCache{}, err
 	}
-	defer cleanup()
 
-	devicePods, err := listPods(c)
+	// the allocatable resources are not available on old kubelets serving v1alpha1 only
+	allocatableResources, err := getAllocatableResources(p.conn)
+	if err != nil {
+		logger.Debug().Err(err).Msg("failed to get allocatable resources")
+	}
 
+	// the malformed device ids are skipped, while the others are still attributed
+	deviceWise, coreWise, err := buildPodInfoCache(devicePods, cores)
 	if err != nil {
-		return nil, nil, err
+		logger.Error().Err(err).Msg("failed to parse allocated device ids")
 	}
 
+	allocatable, err := buildAllocatableCache(allocatableResources, cores)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to parse allocatable device ids")
+	}
+
+	return kubeletCache{
+		deviceWise:  deviceWise,
+		coreWise:    coreWise,
+		allocatable: allocatable,
+	}, nil
+}
+
+// buildPodInfoCache returns the pods using each device and core, allocated either through the device plugin or DRA
+// ResourceClaims. The device ids that cannot be parsed are skipped and returned as errors. The pod resources API does
+// not report the pod uid, which is left to the pod informer.
+func buildPodInfoCache(devicePods *podResourcesAPI.ListPodResourcesResponse, cores deviceCores) (deviceWiseCache, coreWiseCache, error) {
+	deviceWise := make(deviceWiseCache)
+	coreWise := make(coreWiseCache)
+
+	addPodInfo := func(deviceUUID string, podInformation podInfo) {
+		// build device wise cache
+		deviceWise[deviceUUID] = append(deviceWise[deviceUUID], podInformation)
+
+		// build core wise cache
+		if _, ok := coreWise[deviceUUID]; !ok {
+			coreWise[deviceUUID] = make(coreToPodInfo)
+		}
+
+		for _, coreIdx := range podInformation.AllocatedPE {
+			coreWise[deviceUUID][coreIdx] = podInformation
+		}
+	}
+
+	errs := make([]error, 0)
 	for _, podResource := range devicePods.GetPodResources() {
 		for _, containerResource := range podResource.GetContainers() {
 			for _, containerDevice := range containerResource.GetDevices() {
@@ -202,53 +604,110 @@ func buildMultiWiseCache() (deviceWiseCache, coreWiseCache, error) {
 				}
 
 				for _, deviceID := range containerDevice.GetDeviceIds() {
-					deviceUUID := strings.Split(deviceID, furiosaPartitionedResourcePattern)[0]
-					allocatedPE := getAllocatedPEfromDeviceID(deviceID)
+					deviceUUID, allocatedPE, coreLabel, err := cores.parseDeviceID(deviceID)
+					if err != nil {
+						errs = append(errs, err)
+						continue
+					}
 
-					podInformation := podInfo{
+					addPodInfo(deviceUUID, podInfo{
 						Name:          podResource.GetName(),
 						Namespace:     podResource.GetNamespace(),
 						ContainerName: containerResource.GetName(),
+						Topology:      formatTopology(containerDevice.GetTopology()),
 						AllocatedPE:   allocatedPE,
-						CoreLabel:     getCoreLabelfromDeviceID(deviceID),
-					}
-
-					// build device wise cache
-					deviceWise[deviceUUID] = append(deviceWise[deviceUUID], podInformation)
+						CoreLabel:     coreLabel,
+					})
+				}
+			}
 
-					// build core wise cache
-					if _, ok := coreWise[deviceUUID]; !ok {
-						coreWise[deviceUUID] = make(coreToPodInfo)
+			for _, dynamicResource := range containerResource.GetDynamicResources() {
+				for _, claimResource := range dynamicResource.GetClaimResources() {
+					if !isFuriosaDriver(claimResource.GetDriverName()) {
+						continue
 					}
 
-					for _, coreIdx := range allocatedPE {
-						coreWise[deviceUUID][coreIdx] = podInformation
+					deviceUUID, allocatedPE, coreLabel, err := cores.parseClaimResource(claimResource)
+					if err != nil {
+						errs = append(errs, fmt.Errorf("claim '%s/%s': %w", dynamicResource.GetClaimNamespace(), dynamicResource.GetClaimName(), err))
+						continue
 					}
+
+					addPodInfo(deviceUUID, podInfo{
+						Name:           podResource.GetName(),
+						Namespace:      podResource.GetNamespace(),
+						ContainerName:  containerResource.GetName(),
+						ClaimName:      dynamicResource.GetClaimName(),
+						ClaimNamespace: dynamicResource.GetClaimNamespace(),
+						AllocatedPE:    allocatedPE,
+						CoreLabel:      coreLabel,
+					})
 				}
 			}
 		}
 	}
 
+	if len(errs) > 0 {
+		return deviceWise, coreWise, errors.Join(errs...)
+	}
+
 	return deviceWise, coreWise, nil
 }
 
-func connectToServer() (*grpc.ClientConn, func(), error) {
-	resolver.SetDefaultScheme("passthrough")
+// isFuriosaDriver returns whether the DRA driver is served by Furiosa, such as "furiosa.ai" or "npu.furiosa.ai".
+func isFuriosaDriver(driverName string) bool {
+	return driverName == furiosaResourcePrefix || strings.HasSuffix(driverName, "."+furiosaResourcePrefix)
+}
 
-	conn, err := grpc.NewClient(k8sSocket,
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-		grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
-			d := net.Dialer{}
-			return d.DialContext(ctx, "unix", addr)
-		}))
+// buildAllocatableCache returns the allocatable cores of each device, or nil if the allocatable resources are unknown.
+// The device ids that cannot be parsed are skipped and returned as errors.
+func buildAllocatableCache(allocatableResources *podResourcesAPI.AllocatableResourcesResponse, cores deviceCores) (allocatableCache, error) {
+	if allocatableResources == nil {
+		return nil, nil
+	}
 
-	if err != nil {
-		return nil, func() {}, fmt.Errorf("failed to connect to '%s'; err: %w", k8sSocket, err)
+	allocatable := make(allocatableCache)
+	errs := make([]error, 0)
+	for _, containerDevice := range allocatableResources.GetDevices() {
+		if !strings.HasPrefix(containerDevice.GetResourceName(), furiosaResourcePrefix) {
+			continue
+		}
+
+		for _, deviceID := range containerDevice.GetDeviceIds() {
+			deviceUUID, allocatedPE, _, err := cores.parseDeviceID(deviceID)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+
+			if _, ok := allocatable[deviceUUID]; !ok {
+				allocatable[deviceUUID] = make(map[int]bool)
+			}
+
+			for _, coreIdx := range allocatedPE {
+				allocatable[deviceUUID][coreIdx] = true
+			}
+		}
 	}
 
-	return conn, func() { conn.Close() }, nil
+	if len(errs) > 0 {
+		return allocatable, errors.Join(errs...)
+	}
+
+	return allocatable, nil
 }
 
+// formatTopology formats the NUMA nodes hinted by the kubelet such as "0" or "0,1".
+func formatTopology(topology *podResourcesAPI.TopologyInfo) string {
+	nodes := make([]string, 0, len(topology.GetNodes()))
+	for _, node := range topology.GetNodes() {
+		nodes = append(nodes, strconv.FormatInt(node.GetID(), 10))
+	}
+
+	return strings.Join(nodes, ",")
+}
+
+// listPods lists the pod resources with the v1 API, falling back to v1alpha1 on old kubelets.
 func listPods(conn *grpc.ClientConn) (*podResourcesAPI.ListPodResourcesResponse, error) {
 	client := podResourcesAPI.NewPodResourcesListerClient(conn)
 
@@ -256,38 +715,183 @@ func listPods(conn *grpc.ClientConn) (*podResourcesAPI.ListPodResourcesResponse,
 	defer cancel()
 
 	resp, err := client.List(ctx, &podResourcesAPI.ListPodResourcesRequest{})
+	if status.Code(err) == codes.Unimplemented {
+		return listPodsV1Alpha1(conn)
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get pod resources; err: %w\n", err)
+	}
+
+	return resp, nil
+}
+
+// listPodsV1Alpha1 lists the pod resources with the v1alpha1 API, converted to the v1 response.
+func listPodsV1Alpha1(conn *grpc.ClientConn) (*podResourcesAPI.ListPodResourcesResponse, error) {
+	client := podResourcesV1Alpha1API.NewPodResourcesListerClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	resp, err := client.List(ctx, &podResourcesV1Alpha1API.ListPodResourcesRequest{})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get pod resources; err: %w\n", err)
 	}
 
+	converted := &podResourcesAPI.ListPodResourcesResponse{}
+	for _, podResource := range resp.GetPodResources() {
+		convertedPod := &podResourcesAPI.PodResources{
+			Name:      podResource.GetName(),
+			Namespace: podResource.GetNamespace(),
+		}
+
+		for _, containerResource := range podResource.GetContainers() {
+			convertedContainer := &podResourcesAPI.ContainerResources{Name: containerResource.GetName()}
+			for _, containerDevice := range containerResource.GetDevices() {
+				convertedContainer.Devices = append(convertedContainer.Devices, &podResourcesAPI.ContainerDevices{
+					ResourceName: containerDevice.GetResourceName(),
+					DeviceIds:    containerDevice.GetDeviceIds(),
+				})
+			}
+
+			convertedPod.Containers = append(convertedPod.Containers, convertedContainer)
+		}
+
+		converted.PodResources = append(converted.PodResources, convertedPod)
+	}
+
+	return converted, nil
+}
+
+func getAllocatableResources(conn *grpc.ClientConn) (*podResourcesAPI.AllocatableResourcesResponse, error) {
+	client := podResourcesAPI.NewPodResourcesListerClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	resp, err := client.GetAllocatableResources(ctx, &podResourcesAPI.AllocatableResourcesRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get allocatable resources; err: %w\n", err)
+	}
+
 	return resp, nil
 }
 
-func getAllocatedPEfromDeviceID(deviceID string) []int {
-	if !strings.Contains(deviceID, furiosaPartitionedResourcePattern) {
-		return []int{0, 1, 2, 3, 4, 5, 6, 7} // TODO(jongchan): warboy case?
-	} else {
-		cores := strings.Split(deviceID, furiosaPartitionedResourcePattern)[1]
-		coreRange := strings.Split(cores, "-")
-		if len(coreRange) == 1 {
-			n, _ := strconv.Atoi(coreRange[0])
-			return []int{n}
-		} else {
-			n, _ := strconv.Atoi(coreRange[0])
-			m, _ := strconv.Atoi(coreRange[1])
-			allocatedPE := []int{}
-			for i := n; i <= m; i++ {
-				allocatedPE = append(allocatedPE, i)
+// buildDeviceCores returns the cores of each device by uuid, from the device files or the core count of the device.
+func buildDeviceCores(devices []smi.Device) (deviceCores, error) {
+	cores := make(deviceCores, len(devices))
+	for _, d := range devices {
+		info, err := getDeviceInfo(d)
+		if err != nil {
+			return nil, err
+		}
+
+		deviceCoreIdx := make([]int, 0, info.coreNum)
+		for _, c := range uniqueSortedCores(info.cores) {
+			deviceCoreIdx = append(deviceCoreIdx, int(c))
+		}
+
+		if len(deviceCoreIdx) == 0 {
+			for c := 0; c < int(info.coreNum); c++ {
+				deviceCoreIdx = append(deviceCoreIdx, c)
 			}
-			return allocatedPE
 		}
+
+		cores[info.uuid] = deviceCoreIdx
 	}
+
+	return cores, nil
 }
 
-func getCoreLabelfromDeviceID(deviceID string) string {
+// parseDeviceID parses a device ID advertised by the device plugin, either `<uuid>` for a whole device or
+// `<uuid>_cores_<start>[-<end>]` for a partition, into the device uuid, the allocated cores and the core label. The ID
+// is rejected if the device is unknown, or the cores are malformed or out of range of the device.
+func (d deviceCores) parseDeviceID(deviceID string) (string, []int, string, error) {
+	parts := strings.Split(deviceID, furiosaPartitionedResourcePattern)
+	if len(parts) > 2 || parts[0] == "" {
+		return "", nil, "", fmt.Errorf("malformed device id '%s'", deviceID)
+	}
+
+	deviceUUID := parts[0]
+	cores, found := d[deviceUUID]
+	if !found || len(cores) == 0 {
+		return "", nil, "", fmt.Errorf("unknown device of device id '%s'", deviceID)
+	}
+
+	if len(parts) == 1 {
+		return deviceUUID, cores, formatCoreLabel(toUint32Cores(cores)), nil
+	}
+
+	coreRange := strings.Split(parts[1], "-")
+	for _, c := range coreRange {
+		if _, err := strconv.ParseUint(c, 10, 32); err != nil || len(coreRange) > 2 {
+			return "", nil, "", fmt.Errorf("malformed cores of device id '%s'", deviceID)
+		}
+	}
+
+	allocatedPE := parseCoreLabel(parts[1])
+	if len(allocatedPE) == 0 {
+		return "", nil, "", fmt.Errorf("malformed cores of device id '%s'", deviceID)
+	}
+
+	for _, c := range allocatedPE {
+		if !slices.Contains(cores, c) {
+			return "", nil, "", fmt.Errorf("cores of device id '%s' are out of range of the device", deviceID)
+		}
+	}
+
+	return deviceUUID, allocatedPE, formatCoreLabel(toUint32Cores(allocatedPE)), nil
+}
+
+// parseClaimResource resolves the device claimed through DRA from the CDI device names (`<vendor>/<class>=<id>`), or
+// else the device name of the claim resource, where the id is in the format of the device plugin. As the DRA device
+// names are DNS labels, the uuid is matched case-insensitively and `-cores-` is accepted as the partition separator.
+func (d deviceCores) parseClaimResource(claimResource *podResourcesAPI.ClaimResource) (string, []int, string, error) {
+	candidates := make([]string, 0, len(claimResource.GetCDIDevices())+1)
+	for _, cdiDevice := range claimResource.GetCDIDevices() {
+		if _, id, found := strings.Cut(cdiDevice.GetName(), "="); found {
+			candidates = append(candidates, id)
+		}
+	}
+	candidates = append(candidates, claimResource.GetDeviceName())
+
+	for _, candidate := range candidates {
+		deviceUUID, allocatedPE, coreLabel, err := d.parseDeviceID(d.normalizeClaimedDeviceID(candidate))
+		if err == nil {
+			return deviceUUID, allocatedPE, coreLabel, nil
+		}
+	}
+
+	return "", nil, "", fmt.Errorf("failed to resolve the device '%s' of pool '%s'", claimResource.GetDeviceName(), claimResource.GetPoolName())
+}
+
+// normalizeClaimedDeviceID converts a claimed device id such as `<lowercase uuid>-cores-0-3` to the format of the device
+// plugin.
+func (d deviceCores) normalizeClaimedDeviceID(deviceID string) string {
 	if !strings.Contains(deviceID, furiosaPartitionedResourcePattern) {
-		return "0-7" // TODO(jongchan): warboy case?
-	} else {
-		return strings.Split(deviceID, furiosaPartitionedResourcePattern)[1]
+		deviceID = strings.Replace(deviceID, "-cores-", furiosaPartitionedResourcePattern, 1)
+	}
+
+	deviceUUID, partition, partitioned := strings.Cut(deviceID, furiosaPartitionedResourcePattern)
+	if _, found := d[deviceUUID]; !found {
+		for knownUUID := range d {
+			if strings.EqualFold(knownUUID, deviceUUID) {
+				deviceUUID = knownUUID
+				break
+			}
+		}
 	}
+
+	if !partitioned {
+		return deviceUUID
+	}
+
+	return deviceUUID + furiosaPartitionedResourcePattern + partition
+}
+
+func toUint32Cores(cores []int) []uint32 {
+	converted := make([]uint32, 0, len(cores))
+	for _, c := range cores {
+		converted = append(converted, uint32(c))
+	}
+
+	return converted
 }
@@ -0,0 +1,648 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/furiosa-ai/furiosa-smi-go/pkg/smi"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	podResourcesAPI "k8s.io/kubelet/pkg/apis/podresources/v1"
+	podResourcesV1Alpha1API "k8s.io/kubelet/pkg/apis/podresources/v1alpha1"
+)
+
+type fakeV1Alpha1PodResourcesServer struct {
+	podResourcesV1Alpha1API.UnimplementedPodResourcesListerServer
+	resp *podResourcesV1Alpha1API.ListPodResourcesResponse
+}
+
+func (f *fakeV1Alpha1PodResourcesServer) List(context.Context, *podResourcesV1Alpha1API.ListPodResourcesRequest) (*podResourcesV1Alpha1API.ListPodResourcesResponse, error) {
+	return f.resp, nil
+}
+
+// serveFakeKubelet serves the given pod resources servers on a unix socket, and returns the socket path.
+func serveFakeKubelet(t *testing.T, register func(s *grpc.Server)) (string, *grpc.Server) {
+	socket := filepath.Join(t.TempDir(), "kubelet.sock")
+	listener, err := net.Listen("unix", socket)
+	assert.NoError(t, err)
+
+	server := grpc.NewServer()
+	register(server)
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+
+	return socket, server
+}
+
+// startFakeKubelet serves the given pod resources servers on a unix socket, and returns a client connection to it.
+func startFakeKubelet(t *testing.T, register func(s *grpc.Server)) *grpc.ClientConn {
+	socket, _ := serveFakeKubelet(t, register)
+
+	conn, err := grpc.NewClient("unix://"+socket, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return conn
+}
+
+func TestListPods_V1Alpha1Fallback(t *testing.T) {
+	conn := startFakeKubelet(t, func(s *grpc.Server) {
+		podResourcesV1Alpha1API.RegisterPodResourcesListerServer(s, &fakeV1Alpha1PodResourcesServer{
+			resp: &podResourcesV1Alpha1API.ListPodResourcesResponse{
+				PodResources: []*podResourcesV1Alpha1API.PodResources{
+					{
+						Name:      "pod-a",
+						Namespace: "default",
+						Containers: []*podResourcesV1Alpha1API.ContainerResources{
+							{
+								Name: "main",
+								Devices: []*podResourcesV1Alpha1API.ContainerDevices{
+									{ResourceName: "furiosa.ai/rngd", DeviceIds: []string{"A76AAD68-6855-40B1-9E86-D080852D1C80"}},
+								},
+							},
+						},
+					},
+				},
+			},
+		})
+	})
+
+	// the v1 service is not served by the fake kubelet, so the v1alpha1 response is converted
+	resp, err := listPods(conn)
+	assert.NoError(t, err)
+
+	deviceWise, _, err := buildPodInfoCache(resp, newMockDeviceCores(t, smi.ArchRngd))
+	assert.NoError(t, err)
+	assert.Equal(t, deviceWiseCache{
+		"A76AAD68-6855-40B1-9E86-D080852D1C80": {
+			{
+				Name:          "pod-a",
+				Namespace:     "default",
+				ContainerName: "main",
+				AllocatedPE:   []int{0, 1, 2, 3, 4, 5, 6, 7},
+				CoreLabel:     "0-7",
+			},
+		},
+	}, deviceWise)
+
+	// the allocatable resources are unknown on v1alpha1 only kubelets
+	allocatableResources, err := getAllocatableResources(conn)
+	assert.Error(t, err)
+
+	allocatable, err := buildAllocatableCache(allocatableResources, newMockDeviceCores(t, smi.ArchRngd))
+	assert.NoError(t, err)
+	assert.Nil(t, allocatable)
+}
+
+func TestNewKubeResourcesMapper_Disabled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	devices := []smi.Device{unreadableDevice{smi.GetStaticMockDevice(smi.ArchRngd, 0)}}
+
+	// the devices are not read while the mapper is disabled
+	_, _, err := NewKubeResourcesMapper(ctx, zerolog.Nop(), KubeResourcesMapperOptions{}, devices)
+	assert.NoError(t, err)
+
+	_, _, err = NewKubeResourcesMapper(ctx, zerolog.Nop(), KubeResourcesMapperOptions{
+		Enabled:       true,
+		KubeletSocket: filepath.Join(t.TempDir(), "kubelet.sock"),
+	}, devices)
+	assert.Error(t, err)
+}
+
+func TestKubeResourcesMapper_CacheTTL(t *testing.T) {
+	socket, server := serveFakeKubelet(t, func(s *grpc.Server) {
+		podResourcesV1Alpha1API.RegisterPodResourcesListerServer(s, &fakeV1Alpha1PodResourcesServer{
+			resp: &podResourcesV1Alpha1API.ListPodResourcesResponse{
+				PodResources: []*podResourcesV1Alpha1API.PodResources{
+					{
+						Name:      "pod-a",
+						Namespace: "default",
+						Containers: []*podResourcesV1Alpha1API.ContainerResources{
+							{
+								Name: "main",
+								Devices: []*podResourcesV1Alpha1API.ContainerDevices{
+									{ResourceName: "furiosa.ai/rngd", DeviceIds: []string{"A76AAD68-6855-40B1-9E86-D080852D1C80"}},
+								},
+							},
+						},
+					},
+				},
+			},
+		})
+	})
+
+	client, err := newPodResourcesClient(socket)
+	assert.NoError(t, err)
+	t.Cleanup(client.close)
+
+	now := time.Unix(1700000000, 0)
+	mapper := &kubeResourcesMapper{
+		enabled:         true,
+		logger:          zerolog.Nop(),
+		client:          client,
+		cacheTTL:        time.Minute,
+		now:             func() time.Time { return now },
+		deviceCores:     newMockDeviceCores(t, smi.ArchRngd),
+		deviceWiseCache: make(deviceWiseCache),
+	}
+
+	mapper.syncPodInfoCache()
+	assert.Len(t, mapper.deviceWiseCache, 1)
+
+	// the kubelet restarts, so the socket disappears
+	server.Stop()
+
+	now = now.Add(30 * time.Second)
+	mapper.syncPodInfoCache()
+	assert.Len(t, mapper.deviceWiseCache, 1, "the last pod information is kept within the ttl")
+
+	now = now.Add(time.Minute)
+	mapper.syncPodInfoCache()
+	assert.Empty(t, mapper.deviceWiseCache, "the last pod information expires after the ttl")
+}
+
+// newFakePodResources returns the pod resources of the container "main" of "default/pod-a" using the given devices.
+func newFakePodResources(t *testing.T, deviceIDs ...string) *podResourcesAPI.ListPodResourcesResponse {
+	t.Helper()
+
+	return &podResourcesAPI.ListPodResourcesResponse{
+		PodResources: []*podResourcesAPI.PodResources{
+			{
+				Name:      "pod-a",
+				Namespace: "default",
+				Containers: []*podResourcesAPI.ContainerResources{
+					{
+						Name: "main",
+						Devices: []*podResourcesAPI.ContainerDevices{
+							{ResourceName: "furiosa.ai/rngd", DeviceIds: deviceIDs},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func newMockDeviceCores(t *testing.T, arch smi.Arch) deviceCores {
+	cores, err := buildDeviceCores(smi.GetStaticMockDevices(arch))
+	assert.NoError(t, err)
+
+	return cores
+}
+
+func TestParseDeviceID(t *testing.T) {
+	deviceUUID := "A76AAD68-6855-40B1-9E86-D080852D1C80"
+
+	tests := []struct {
+		description         string
+		arch                smi.Arch
+		deviceID            string
+		expectedAllocatedPE []int
+		expectedCoreLabel   string
+		expectedErr         bool
+	}{
+		{
+			description:         "whole rngd device",
+			arch:                smi.ArchRngd,
+			deviceID:            deviceUUID,
+			expectedAllocatedPE: []int{0, 1, 2, 3, 4, 5, 6, 7},
+			expectedCoreLabel:   "0-7",
+		},
+		{
+			description:         "whole warboy device",
+			arch:                smi.ArchWarboy,
+			deviceID:            deviceUUID,
+			expectedAllocatedPE: []int{0, 1},
+			expectedCoreLabel:   "0-1",
+		},
+		{
+			description:         "rngd partition",
+			arch:                smi.ArchRngd,
+			deviceID:            deviceUUID + "_cores_4-7",
+			expectedAllocatedPE: []int{4, 5, 6, 7},
+			expectedCoreLabel:   "4-7",
+		},
+		{
+			description:         "single core partition",
+			arch:                smi.ArchWarboy,
+			deviceID:            deviceUUID + "_cores_1",
+			expectedAllocatedPE: []int{1},
+			expectedCoreLabel:   "1",
+		},
+		{
+			description: "partition out of range of warboy",
+			arch:        smi.ArchWarboy,
+			deviceID:    deviceUUID + "_cores_0-3",
+			expectedErr: true,
+		},
+		{
+			description: "non numeric cores",
+			arch:        smi.ArchRngd,
+			deviceID:    deviceUUID + "_cores_a",
+			expectedErr: true,
+		},
+		{
+			description: "reversed cores",
+			arch:        smi.ArchRngd,
+			deviceID:    deviceUUID + "_cores_3-1",
+			expectedErr: true,
+		},
+		{
+			description: "too many core ranges",
+			arch:        smi.ArchRngd,
+			deviceID:    deviceUUID + "_cores_0-1-2",
+			expectedErr: true,
+		},
+		{
+			description: "empty uuid",
+			arch:        smi.ArchRngd,
+			deviceID:    "_cores_0",
+			expectedErr: true,
+		},
+		{
+			description: "unknown device",
+			arch:        smi.ArchRngd,
+			deviceID:    "00000000-0000-0000-0000-000000000000",
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			parsedUUID, allocatedPE, coreLabel, err := newMockDeviceCores(t, tc.arch).parseDeviceID(tc.deviceID)
+			if tc.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, deviceUUID, parsedUUID)
+			assert.Equal(t, tc.expectedAllocatedPE, allocatedPE)
+			assert.Equal(t, tc.expectedCoreLabel, coreLabel)
+		})
+	}
+}
+
+func TestKubeResourcesMapper_WarboyExclusiveAllocation(t *testing.T) {
+	device := smi.GetStaticMockDevice(smi.ArchWarboy, 0)
+	cores := newMockDeviceCores(t, smi.ArchWarboy)
+
+	deviceWise, coreWise, err := buildPodInfoCache(&podResourcesAPI.ListPodResourcesResponse{
+		PodResources: []*podResourcesAPI.PodResources{
+			{
+				Name:      "pod-a",
+				Namespace: "default",
+				Containers: []*podResourcesAPI.ContainerResources{
+					{
+						Name: "main",
+						Devices: []*podResourcesAPI.ContainerDevices{
+							{ResourceName: "furiosa.ai/warboy", DeviceIds: []string{"A76AAD68-6855-40B1-9E86-D080852D1C80", "A76AAD68-6855-40B1-9E86-D080852D1C80_cores_9"}},
+						},
+					},
+				},
+			},
+		},
+	}, cores)
+	assert.Error(t, err, "the malformed device id is rejected")
+
+	mapper := &kubeResourcesMapper{
+		enabled:         true,
+		deviceCores:     cores,
+		deviceWiseCache: deviceWise,
+		coreWiseCache:   coreWise,
+	}
+
+	metric, err := NewMetricFactory("", "", false).NewDeviceWiseMetric(device)
+	assert.NoError(t, err)
+
+	// the warboy device of two cores is allocated exclusively, so the metric is attributed instead of duplicated
+	transformed := mapper.TransformDeviceMetrics(MetricContainer{metric}, false)
+	assert.Len(t, transformed, 1)
+	assert.Equal(t, "pod-a", transformed[0][kubernetesPod])
+	assert.Equal(t, "0-1", transformed[0][core])
+}
+
+func TestBuildPodInfoCache_DynamicResources(t *testing.T) {
+	deviceUUID := "A76AAD68-6855-40B1-9E86-D080852D1C80"
+	devices := smi.GetStaticMockDevices(smi.ArchRngd)[:2]
+	cores, err := buildDeviceCores(devices)
+	assert.NoError(t, err)
+
+	deviceWise, coreWise, err := buildPodInfoCache(&podResourcesAPI.ListPodResourcesResponse{
+		PodResources: []*podResourcesAPI.PodResources{
+			{
+				Name:      "pod-a",
+				Namespace: "default",
+				Containers: []*podResourcesAPI.ContainerResources{
+					{
+						Name: "main",
+						DynamicResources: []*podResourcesAPI.DynamicResource{
+							{
+								ClaimName:      "npu-claim",
+								ClaimNamespace: "default",
+								ClaimResources: []*podResourcesAPI.ClaimResource{
+									{
+										// the partition is resolved from the cdi device
+										CDIDevices: []*podResourcesAPI.CDIDevice{{Name: "furiosa.ai/npu=" + deviceUUID + "_cores_0-3"}},
+										DriverName: "npu.furiosa.ai",
+										PoolName:   "node-a",
+										DeviceName: "npu0-cores-0-3",
+									},
+									{
+										// the whole device is resolved from the lowercase device name
+										DriverName: "npu.furiosa.ai",
+										PoolName:   "node-a",
+										DeviceName: "a76aad68-6855-40b1-9e86-d080852d1c81",
+									},
+									{
+										DriverName: "gpu.example.com",
+										PoolName:   "node-a",
+										DeviceName: "gpu0",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}, cores)
+	assert.NoError(t, err)
+
+	assert.Equal(t, deviceWiseCache{
+		deviceUUID: {
+			{
+				Name:           "pod-a",
+				Namespace:      "default",
+				ContainerName:  "main",
+				ClaimName:      "npu-claim",
+				ClaimNamespace: "default",
+				AllocatedPE:    []int{0, 1, 2, 3},
+				CoreLabel:      "0-3",
+			},
+		},
+		"A76AAD68-6855-40B1-9E86-D080852D1C81": {
+			{
+				Name:           "pod-a",
+				Namespace:      "default",
+				ContainerName:  "main",
+				ClaimName:      "npu-claim",
+				ClaimNamespace: "default",
+				AllocatedPE:    []int{0, 1, 2, 3, 4, 5, 6, 7},
+				CoreLabel:      "0-7",
+			},
+		},
+	}, deviceWise)
+
+	mapper := &kubeResourcesMapper{
+		enabled:         true,
+		deviceCores:     cores,
+		deviceWiseCache: deviceWise,
+		coreWiseCache:   coreWise,
+	}
+
+	metric, err := NewMetricFactory("", "", false).NewDeviceWiseMetric(devices[1])
+	assert.NoError(t, err)
+
+	transformed := mapper.TransformDeviceMetrics(MetricContainer{metric}, false)
+	assert.Len(t, transformed, 1)
+	assert.Equal(t, "npu-claim", transformed[0][claimName])
+	assert.Equal(t, "default", transformed[0][claimNamespace])
+
+	// the claimed device cannot be resolved
+	_, _, err = buildPodInfoCache(&podResourcesAPI.ListPodResourcesResponse{
+		PodResources: []*podResourcesAPI.PodResources{
+			{
+				Name:      "pod-b",
+				Namespace: "default",
+				Containers: []*podResourcesAPI.ContainerResources{
+					{
+						Name: "main",
+						DynamicResources: []*podResourcesAPI.DynamicResource{
+							{
+								ClaimName:      "npu-claim",
+								ClaimNamespace: "default",
+								ClaimResources: []*podResourcesAPI.ClaimResource{
+									{DriverName: "furiosa.ai", PoolName: "node-a", DeviceName: "npu9"},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}, cores)
+	assert.Error(t, err)
+}
+
+func TestBuildPodInfoCache_Topology(t *testing.T) {
+	deviceWise, coreWise, err := buildPodInfoCache(&podResourcesAPI.ListPodResourcesResponse{
+		PodResources: []*podResourcesAPI.PodResources{
+			{
+				Name:      "pod-a",
+				Namespace: "default",
+				Containers: []*podResourcesAPI.ContainerResources{
+					{
+						Name: "main",
+						Devices: []*podResourcesAPI.ContainerDevices{
+							{
+								ResourceName: "furiosa.ai/rngd-4core",
+								DeviceIds:    []string{"A76AAD68-6855-40B1-9E86-D080852D1C80_cores_0-3"},
+								Topology: &podResourcesAPI.TopologyInfo{
+									Nodes: []*podResourcesAPI.NUMANode{{ID: 0}, {ID: 1}},
+								},
+							},
+							{
+								ResourceName: "example.com/gpu",
+								DeviceIds:    []string{"gpu0"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}, newMockDeviceCores(t, smi.ArchRngd))
+	assert.NoError(t, err)
+
+	assert.Len(t, deviceWise, 1)
+	assert.Equal(t, "0,1", deviceWise["A76AAD68-6855-40B1-9E86-D080852D1C80"][0].Topology)
+	assert.Len(t, coreWise["A76AAD68-6855-40B1-9E86-D080852D1C80"], 4)
+}
+
+func TestAllocationCollector_Collect(t *testing.T) {
+	deviceUUID := "A76AAD68-6855-40B1-9E86-D080852D1C80"
+	devices := []smi.Device{smi.GetStaticMockDevice(smi.ArchRngd, 0)}
+
+	cores := newMockDeviceCores(t, smi.ArchRngd)
+
+	deviceWise, coreWise, err := buildPodInfoCache(&podResourcesAPI.ListPodResourcesResponse{
+		PodResources: []*podResourcesAPI.PodResources{
+			{
+				Name:      "pod-a",
+				Namespace: "default",
+				Containers: []*podResourcesAPI.ContainerResources{
+					{
+						Name: "main",
+						Devices: []*podResourcesAPI.ContainerDevices{
+							{ResourceName: "furiosa.ai/rngd-2core", DeviceIds: []string{deviceUUID + "_cores_0-1"}},
+						},
+					},
+				},
+			},
+		},
+	}, cores)
+	assert.NoError(t, err)
+
+	// the cores 6-7 are not advertised by the device plugin
+	allocatable, err := buildAllocatableCache(&podResourcesAPI.AllocatableResourcesResponse{
+		Devices: []*podResourcesAPI.ContainerDevices{
+			{ResourceName: "furiosa.ai/rngd-2core", DeviceIds: []string{deviceUUID + "_cores_0-1", deviceUUID + "_cores_2-3", deviceUUID + "_cores_4-5"}},
+		},
+	}, cores)
+	assert.NoError(t, err)
+
+	kubeResMapper := &kubeResourcesMapper{
+		enabled:          true,
+		deviceCores:      cores,
+		deviceWiseCache:  deviceWise,
+		coreWiseCache:    coreWise,
+		allocatableCache: allocatable,
+	}
+
+	collector := NewAllocationCollector(devices, NewMetricFactory("", "", false), kubeResMapper)
+	useTestRegistry(t)
+	collector.Register()
+
+	err = collector.Collect()
+	assert.NoError(t, err)
+
+	expected := `
+# HELP furiosa_npu_allocation_state The allocation state (allocated, allocatable, unavailable) of NPU core according to the kubelet. The value is always 1
+# TYPE furiosa_npu_allocation_state gauge
+furiosa_npu_allocation_state{arch="rngd",container="main",core="0",device="npu0",firmware_version="1.6.0+c1bebfd",namespace="default",pci_bus_id="0000:27:00.0",pert_version="0.0.0+",pod="pod-a",state="allocated",uuid="A76AAD68-6855-40B1-9E86-D080852D1C80"} 1
+furiosa_npu_allocation_state{arch="rngd",container="main",core="1",device="npu0",firmware_version="1.6.0+c1bebfd",namespace="default",pci_bus_id="0000:27:00.0",pert_version="0.0.0+",pod="pod-a",state="allocated",uuid="A76AAD68-6855-40B1-9E86-D080852D1C80"} 1
+furiosa_npu_allocation_state{arch="rngd",core="2",device="npu0",firmware_version="1.6.0+c1bebfd",pci_bus_id="0000:27:00.0",pert_version="0.0.0+",state="allocatable",uuid="A76AAD68-6855-40B1-9E86-D080852D1C80"} 1
+furiosa_npu_allocation_state{arch="rngd",core="3",device="npu0",firmware_version="1.6.0+c1bebfd",pci_bus_id="0000:27:00.0",pert_version="0.0.0+",state="allocatable",uuid="A76AAD68-6855-40B1-9E86-D080852D1C80"} 1
+furiosa_npu_allocation_state{arch="rngd",core="4",device="npu0",firmware_version="1.6.0+c1bebfd",pci_bus_id="0000:27:00.0",pert_version="0.0.0+",state="allocatable",uuid="A76AAD68-6855-40B1-9E86-D080852D1C80"} 1
+furiosa_npu_allocation_state{arch="rngd",core="5",device="npu0",firmware_version="1.6.0+c1bebfd",pci_bus_id="0000:27:00.0",pert_version="0.0.0+",state="allocatable",uuid="A76AAD68-6855-40B1-9E86-D080852D1C80"} 1
+furiosa_npu_allocation_state{arch="rngd",core="6",device="npu0",firmware_version="1.6.0+c1bebfd",pci_bus_id="0000:27:00.0",pert_version="0.0.0+",state="unavailable",uuid="A76AAD68-6855-40B1-9E86-D080852D1C80"} 1
+furiosa_npu_allocation_state{arch="rngd",core="7",device="npu0",firmware_version="1.6.0+c1bebfd",pci_bus_id="0000:27:00.0",pert_version="0.0.0+",state="unavailable",uuid="A76AAD68-6855-40B1-9E86-D080852D1C80"} 1
+`
+	err = testutil.GatherAndCompare(prometheus.DefaultGatherer, strings.NewReader(expected), "furiosa_npu_allocation_state")
+	assert.NoError(t, err)
+}
+
+func TestParseAttributionMode(t *testing.T) {
+	mode, err := ParseAttributionMode("")
+	assert.NoError(t, err)
+	assert.Equal(t, AttributionModeDuplicate, mode)
+
+	mode, err = ParseAttributionMode("split")
+	assert.NoError(t, err)
+	assert.Equal(t, AttributionModeSplit, mode)
+
+	_, err = ParseAttributionMode("proportional")
+	assert.Error(t, err)
+}
+
+func TestKubeResourcesMapper_AttributionMode(t *testing.T) {
+	deviceUUID := "A76AAD68-6855-40B1-9E86-D080852D1C80"
+	cores := newMockDeviceCores(t, smi.ArchRngd)
+
+	// the device is partitioned between two pods, leaving the cores 6-7 unallocated
+	deviceWise := deviceWiseCache{
+		deviceUUID: {
+			{Name: "pod-a", Namespace: "default", ContainerName: "main", AllocatedPE: []int{0, 1, 2, 3}, CoreLabel: "0-3"},
+			{Name: "pod-b", Namespace: "default", ContainerName: "main", AllocatedPE: []int{4, 5}, CoreLabel: "4-5"},
+		},
+	}
+
+	tests := []struct {
+		mode     AttributionMode
+		expected []string
+	}{
+		{
+			mode:     "",
+			expected: []string{"/0-7/100", "pod-a/0-3/100", "pod-b/4-5/100"},
+		},
+		{
+			mode:     AttributionModeReplace,
+			expected: []string{"pod-a/0-3/100", "pod-b/4-5/100"},
+		},
+		{
+			mode:     AttributionModeSplit,
+			expected: []string{"pod-a/0-3/50", "pod-b/4-5/25"},
+		},
+		{
+			mode:     AttributionModeInfo,
+			expected: []string{"/0-7/100"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(string(tc.mode), func(t *testing.T) {
+			mapper := &kubeResourcesMapper{
+				enabled:         true,
+				deviceCores:     cores,
+				attributionMode: tc.mode,
+				deviceWiseCache: deviceWise,
+			}
+
+			metric, err := NewMetricFactory("", "", false).NewDeviceWiseMetric(smi.GetStaticMockDevice(smi.ArchRngd, 0))
+			assert.NoError(t, err)
+			metric[rms] = float64(100)
+
+			actual := make([]string, 0)
+			for _, transformed := range mapper.TransformDeviceMetrics(MetricContainer{metric}, false) {
+				actual = append(actual, fmt.Sprintf("%s/%s/%v", transformed[kubernetesPod], transformed[core], transformed[rms]))
+			}
+			assert.Equal(t, tc.expected, actual)
+			assert.Equal(t, float64(100), metric[rms], "the original metric is not modified")
+		})
+	}
+}
+
+func TestAllocationInfoCollector_Collect(t *testing.T) {
+	devices := []smi.Device{smi.GetStaticMockDevice(smi.ArchRngd, 0)}
+	cores := newMockDeviceCores(t, smi.ArchRngd)
+
+	deviceWise, coreWise, err := buildPodInfoCache(newFakePodResources(t, "A76AAD68-6855-40B1-9E86-D080852D1C80_cores_0-3"), cores)
+	assert.NoError(t, err)
+
+	mapper := &kubeResourcesMapper{
+		enabled:         true,
+		deviceCores:     cores,
+		attributionMode: AttributionModeInfo,
+		deviceWiseCache: deviceWise,
+		coreWiseCache:   coreWise,
+	}
+
+	collector := NewAllocationInfoCollector(devices, NewMetricFactory("", "", false), mapper)
+	useTestRegistry(t)
+	collector.Register()
+
+	err = collector.Collect()
+	assert.NoError(t, err)
+
+	expected := `
+# HELP furiosa_npu_allocation_info The pod and container to which the cores of NPU device are allocated. The value is always 1
+# TYPE furiosa_npu_allocation_info gauge
+furiosa_npu_allocation_info{arch="rngd",container="main",core="0-3",device="npu0",firmware_version="1.6.0+c1bebfd",namespace="default",pci_bus_id="0000:27:00.0",pert_version="0.0.0+",pod="pod-a",uuid="A76AAD68-6855-40B1-9E86-D080852D1C80"} 1
+`
+	err = testutil.GatherAndCompare(prometheus.DefaultGatherer, strings.NewReader(expected), "furiosa_npu_allocation_info")
+	assert.NoError(t, err)
+}
@@ -4,6 +4,8 @@ const (
 	arch                = "arch"
 	device              = "device"
 	label               = "label"
+	state               = "state"
+	profile             = "profile"
 	uuid                = "uuid"
 	core                = "core"
 	bdf                 = "pci_bus_id"
@@ -14,4 +16,22 @@ const (
 	kubernetesNamespace = "namespace"
 	kubernetesPod       = "pod"
 	kubernetesContainer = "container"
+	kubernetesPodUID    = "pod_uid"
+	containerID         = "container_id"
+	kubernetesTopology  = "topology"
+	claimName           = "claim"
+	claimNamespace      = "claim_namespace"
+	ownerKind           = "owner_kind"
+	ownerName           = "owner_name"
+	deviceIndex         = "index"
+	serial              = "serial"
+	numaNode            = "numa_node"
+	coreNum             = "core_num"
+	major               = "major"
+	minor               = "minor"
+	filePath            = "path"
+	fileCores           = "cores"
+	processID           = "pid"
+	processComm         = "comm"
+	processUser         = "user"
 )
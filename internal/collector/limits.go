@@ -0,0 +1,118 @@
+package collector
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/furiosa-ai/furiosa-smi-go/pkg/smi"
+)
+
+const (
+	// throttleMarginCelsius is the thermal headroom below which a device running under the nominal core frequency is
+	// regarded as throttled.
+	throttleMarginCelsius = 5
+)
+
+// ArchLimits holds the limits of an NPU architecture used to derive the headroom metrics.
+type ArchLimits struct {
+	// ThermalLimit is the peak temperature at which the device starts throttling (Celsius).
+	ThermalLimit float64
+	// PowerLimit is the maximum power consumption of the device (W).
+	PowerLimit float64
+	// NominalCoreFrequency is the core frequency of the device when it is not throttled (MHz).
+	NominalCoreFrequency uint32
+}
+
+// DeviceLimits maps each NPU architecture to its limits.
+type DeviceLimits map[smi.Arch]ArchLimits
+
+// DefaultDeviceLimits returns the default limits of the known NPU architectures.
+func DefaultDeviceLimits() DeviceLimits {
+	return DeviceLimits{
+		smi.ArchWarboy: {
+			ThermalLimit:         85,
+			PowerLimit:           40,
+			NominalCoreFrequency: 2000,
+		},
+		smi.ArchRngd: {
+			ThermalLimit:         85,
+			PowerLimit:           180,
+			NominalCoreFrequency: 1000,
+		},
+		smi.ArchRngdMax: {
+			ThermalLimit:         85,
+			PowerLimit:           350,
+			NominalCoreFrequency: 1000,
+		},
+		smi.ArchRngdS: {
+			ThermalLimit:         85,
+			PowerLimit:           75,
+			NominalCoreFrequency: 1000,
+		},
+	}
+}
+
+// NewDeviceLimits returns the default limits overridden by the given thermal limits, power limits and nominal core
+// frequencies keyed by arch name (e.g. "rngd").
+func NewDeviceLimits(thermalLimits map[string]float64, powerLimits map[string]float64, nominalCoreFrequencies map[string]float64) (DeviceLimits, error) {
+	limits := DefaultDeviceLimits()
+
+	for archName, value := range thermalLimits {
+		a, found := limits.archByName(archName)
+		if !found {
+			return nil, fmt.Errorf("unknown arch '%s' for thermal limit", archName)
+		}
+
+		archLimits := limits[a]
+		archLimits.ThermalLimit = value
+		limits[a] = archLimits
+	}
+
+	for archName, value := range powerLimits {
+		a, found := limits.archByName(archName)
+		if !found {
+			return nil, fmt.Errorf("unknown arch '%s' for power limit", archName)
+		}
+
+		archLimits := limits[a]
+		archLimits.PowerLimit = value
+		limits[a] = archLimits
+	}
+
+	for archName, value := range nominalCoreFrequencies {
+		a, found := limits.archByName(archName)
+		if !found {
+			return nil, fmt.Errorf("unknown arch '%s' for nominal core frequency", archName)
+		}
+
+		if value <= 0 || value > math.MaxUint32 {
+			return nil, fmt.Errorf("invalid nominal core frequency '%v' for arch '%s'", value, archName)
+		}
+
+		archLimits := limits[a]
+		archLimits.NominalCoreFrequency = uint32(value)
+		limits[a] = archLimits
+	}
+
+	return limits, nil
+}
+
+// forArch returns the limits of the arch with the given name, which is the same as the `arch` label value.
+func (l DeviceLimits) forArch(archName string) (ArchLimits, bool) {
+	a, found := l.archByName(archName)
+	if !found {
+		return ArchLimits{}, false
+	}
+
+	return l[a], true
+}
+
+func (l DeviceLimits) archByName(archName string) (smi.Arch, bool) {
+	for a := range l {
+		if a.ToString() == archName {
+			return a, true
+		}
+	}
+
+	return 0, false
+}
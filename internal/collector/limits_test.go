@@ -0,0 +1,44 @@
+package collector
+
+import (
+	"testing"
+
+	"github.com/furiosa-ai/furiosa-smi-go/pkg/smi"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewDeviceLimits(t *testing.T) {
+	limits, err := NewDeviceLimits(map[string]float64{"rngd": 80}, map[string]float64{"warboy": 50}, map[string]float64{"rngd-max": 1200})
+	assert.NoError(t, err)
+
+	assert.Equal(t, float64(80), limits[smi.ArchRngd].ThermalLimit)
+	assert.Equal(t, DefaultDeviceLimits()[smi.ArchRngd].PowerLimit, limits[smi.ArchRngd].PowerLimit)
+	assert.Equal(t, float64(50), limits[smi.ArchWarboy].PowerLimit)
+	assert.Equal(t, DefaultDeviceLimits()[smi.ArchWarboy].ThermalLimit, limits[smi.ArchWarboy].ThermalLimit)
+
+	assert.Equal(t, DefaultDeviceLimits()[smi.ArchRngd].NominalCoreFrequency, limits[smi.ArchRngd].NominalCoreFrequency)
+
+	archLimits, found := limits.forArch("rngd-max")
+	assert.True(t, found)
+	assert.Equal(t, uint32(1200), archLimits.NominalCoreFrequency)
+	assert.Equal(t, DefaultDeviceLimits()[smi.ArchRngdMax].PowerLimit, archLimits.PowerLimit)
+
+	_, found = limits.forArch("unknown")
+	assert.False(t, found)
+
+	_, err = NewDeviceLimits(map[string]float64{"unknown": 80}, nil, nil)
+	assert.Error(t, err)
+
+	_, err = NewDeviceLimits(nil, nil, map[string]float64{"rngd": 0})
+	assert.Error(t, err)
+}
+
+func TestIsThrottled(t *testing.T) {
+	// the mock device runs all cores at 500MHz
+	coreFrequency, err := smi.GetStaticMockDevice(smi.ArchRngd, 0).CoreFrequency()
+	assert.NoError(t, err)
+
+	assert.True(t, isThrottled(2, coreFrequency, 1000))
+	assert.False(t, isThrottled(20, coreFrequency, 1000))
+	assert.False(t, isThrottled(2, coreFrequency, 500))
+}
@@ -1,29 +1,43 @@
 package collector
 
 import (
+	"context"
 	"errors"
+	"time"
+
 	"github.com/furiosa-ai/furiosa-smi-go/pkg/smi"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
 const (
-	liveness = "liveness"
+	liveness            = "liveness"
+	livenessTransitions = "livenessTransitions"
+	livenessLastChange  = "livenessLastChange"
+	flapping            = "flapping"
 )
 
 type livenessCollector struct {
-	devices       []smi.Device
-	metricFactory MetricFactory
-	gaugeVec      *prometheus.GaugeVec
-	kubeResMapper KubeResourcesMapper
+	devices               []smi.Device
+	metricFactory         MetricFactory
+	gaugeVec              *prometheus.GaugeVec
+	transitionsCounterVec *prometheus.CounterVec
+	lastChangeGaugeVec    *prometheus.GaugeVec
+	flappingGaugeVec      *prometheus.GaugeVec
+	kubeResMapper         KubeResourcesMapper
+	tracker               *livenessTracker
+	fastSamplingInterval  time.Duration
 }
 
 var _ Collector = (*livenessCollector)(nil)
+var _ BackgroundSampler = (*livenessCollector)(nil)
 
-func NewLivenessCollector(devices []smi.Device, metricFactory MetricFactory, kubeResMapper KubeResourcesMapper) Collector {
+func NewLivenessCollector(devices []smi.Device, metricFactory MetricFactory, kubeResMapper KubeResourcesMapper, options LivenessOptions) Collector {
 	return &livenessCollector{
-		devices:       devices,
-		metricFactory: metricFactory,
-		kubeResMapper: kubeResMapper,
+		devices:              devices,
+		metricFactory:        metricFactory,
+		kubeResMapper:        kubeResMapper,
+		tracker:              newLivenessTracker(time.Now, options),
+		fastSamplingInterval: options.FastInterval,
 	}
 }
 
@@ -33,13 +47,90 @@ func (t *livenessCollector) Register() {
 		Help: "The liveness of NPU device",
 	}
 
-	t.gaugeVec = prometheus.NewGaugeVec(opts, defaultMetricLabels())
+	t.gaugeVec = prometheus.NewGaugeVec(opts, defaultMetricLabels(t.kubeResMapper.MetricLabels()...))
 
 	prometheus.MustRegister(NewLabelFilterCollector(
 		t.gaugeVec,
 		prometheus.Opts(opts),
 		prometheus.GaugeValue,
 	))
+
+	transitionsOpts := prometheus.CounterOpts{
+		Name: "furiosa_npu_liveness_transitions_total",
+		Help: "The number of liveness state changes of NPU device since the exporter started",
+	}
+
+	t.transitionsCounterVec = prometheus.NewCounterVec(transitionsOpts, defaultMetricLabels(t.kubeResMapper.MetricLabels()...))
+
+	prometheus.MustRegister(NewLabelFilterCollector(
+		t.transitionsCounterVec,
+		prometheus.Opts(transitionsOpts),
+		prometheus.CounterValue,
+	))
+
+	lastChangeOpts := prometheus.GaugeOpts{
+		Name: "furiosa_npu_liveness_last_change_timestamp_seconds",
+		Help: "The unix timestamp of the last liveness state change of NPU device, or of the first observation if unchanged",
+	}
+
+	t.lastChangeGaugeVec = prometheus.NewGaugeVec(lastChangeOpts, defaultMetricLabels(t.kubeResMapper.MetricLabels()...))
+
+	prometheus.MustRegister(NewLabelFilterCollector(
+		t.lastChangeGaugeVec,
+		prometheus.Opts(lastChangeOpts),
+		prometheus.GaugeValue,
+	))
+
+	flappingOpts := prometheus.GaugeOpts{
+		Name: "furiosa_npu_flapping",
+		Help: "Whether the liveness of NPU device changed more than the threshold within the flapping window",
+	}
+
+	t.flappingGaugeVec = prometheus.NewGaugeVec(flappingOpts, defaultMetricLabels(t.kubeResMapper.MetricLabels()...))
+
+	prometheus.MustRegister(NewLabelFilterCollector(
+		t.flappingGaugeVec,
+		prometheus.Opts(flappingOpts),
+		prometheus.GaugeValue,
+	))
+}
+
+// StartSampling samples the liveness of the devices at the fast sampling interval while any device is recently
+// unhealthy, so that the state changes between two collections are tracked.
+func (t *livenessCollector) StartSampling(ctx context.Context) {
+	if t.fastSamplingInterval <= 0 {
+		return
+	}
+
+	go func() {
+		tick := time.NewTicker(t.fastSamplingInterval)
+		defer tick.Stop()
+
+		for {
+			select {
+			case <-tick.C:
+				if !t.tracker.recentlyUnhealthy() {
+					continue
+				}
+
+				for _, d := range t.devices {
+					info, err := d.DeviceInfo()
+					if err != nil {
+						continue
+					}
+
+					value, err := d.Liveness()
+					if err != nil {
+						continue
+					}
+
+					t.tracker.observe(info.UUID(), value)
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
 }
 
 func (t *livenessCollector) Collect() error {
@@ -60,6 +151,16 @@ func (t *livenessCollector) Collect() error {
 		}
 
 		metric[liveness] = value
+
+		state := t.tracker.observe(metric[uuid].(string), value)
+		metric[livenessTransitions] = float64(state.transitions)
+		metric[livenessLastChange] = float64(state.lastChange.UnixNano()) / float64(time.Second)
+		if t.tracker.isFlapping(state) {
+			metric[flapping] = float64(1)
+		} else {
+			metric[flapping] = float64(0)
+		}
+
 		metricContainer = append(metricContainer, metric)
 	}
 
@@ -75,8 +176,12 @@ func (t *livenessCollector) Collect() error {
 }
 
 func (t *livenessCollector) postProcess(metrics MetricContainer) error {
+	labelNames := defaultMetricLabels(t.kubeResMapper.MetricLabels()...)
 	transformed := t.kubeResMapper.TransformDeviceMetrics(metrics, false)
 	t.gaugeVec.Reset()
+	t.transitionsCounterVec.Reset()
+	t.lastChangeGaugeVec.Reset()
+	t.flappingGaugeVec.Reset()
 
 	for _, metric := range transformed {
 		if value, ok := metric[liveness]; ok {
@@ -87,21 +192,20 @@ func (t *livenessCollector) postProcess(metrics MetricContainer) error {
 				alive = 0
 			}
 
-			t.gaugeVec.With(prometheus.Labels{
-				arch:                metric[arch].(string),
-				core:                metric[core].(string),
-				device:              metric[device].(string),
-				uuid:                metric[uuid].(string),
-				bdf:                 metric[bdf].(string),
-				firmwareVersion:     metric[firmwareVersion].(string),
-				pertVersion:         metric[pertVersion].(string),
-				driverVersion:       metric[driverVersion].(string),
-				hostname:            metric[hostname].(string),
-				kubernetesNamespace: metric[kubernetesNamespace].(string),
-				kubernetesPod:       metric[kubernetesPod].(string),
-				kubernetesContainer: metric[kubernetesContainer].(string),
-			}).Set(alive)
+			t.gaugeVec.With(metricLabels(labelNames, metric, nil)).Set(alive)
+
+		}
+
+		if value, ok := metric[livenessTransitions]; ok {
+			t.transitionsCounterVec.With(metricLabels(labelNames, metric, nil)).Add(value.(float64))
+		}
+
+		if value, ok := metric[livenessLastChange]; ok {
+			t.lastChangeGaugeVec.With(metricLabels(labelNames, metric, nil)).Set(value.(float64))
+		}
 
+		if value, ok := metric[flapping]; ok {
+			t.flappingGaugeVec.With(metricLabels(labelNames, metric, nil)).Set(value.(float64))
 		}
 	}
 
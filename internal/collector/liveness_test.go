@@ -65,6 +65,7 @@ furiosa_npu_alive{arch="rngd",core="0-7",device="npu0",uuid="uuid"} 0
 	}
 
 	collector := newFakeLivenessCollector()
+	useTestRegistry(t)
 	collector.Register()
 	for _, tc := range tests {
 		t.Run(tc.description, func(t *testing.T) {
@@ -77,6 +78,53 @@ furiosa_npu_alive{arch="rngd",core="0-7",device="npu0",uuid="uuid"} 0
 	}
 }
 
+func TestLivenessCollector_PostProcessingTransitions(t *testing.T) {
+	collector := &livenessCollector{
+		kubeResMapper:         NewFakeKubeResourcesMapper(),
+		gaugeVec:              prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "furiosa_npu_alive"}, defaultMetricLabels()),
+		transitionsCounterVec: prometheus.NewCounterVec(prometheus.CounterOpts{Name: "furiosa_npu_liveness_transitions_total"}, defaultMetricLabels()),
+		lastChangeGaugeVec:    prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "furiosa_npu_liveness_last_change_timestamp_seconds"}, defaultMetricLabels()),
+		flappingGaugeVec:      prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "furiosa_npu_flapping"}, defaultMetricLabels()),
+	}
+
+	metric := newMetric()
+	metric[arch] = "rngd"
+	metric[core] = "0-7"
+	metric[device] = "npu0"
+	metric[uuid] = "uuid"
+	metric[liveness] = true
+	metric[livenessTransitions] = float64(4)
+	metric[livenessLastChange] = float64(1013)
+	metric[flapping] = float64(1)
+
+	err := collector.postProcess(MetricContainer{metric})
+	assert.NoError(t, err)
+
+	expected := `
+# HELP furiosa_npu_liveness_transitions_total 
+# TYPE furiosa_npu_liveness_transitions_total counter
+furiosa_npu_liveness_transitions_total{arch="rngd",core="0-7",device="npu0",uuid="uuid"} 4
+`
+	err = testutil.CollectAndCompare(NewLabelFilterCollector(collector.transitionsCounterVec, prometheus.Opts{Name: "furiosa_npu_liveness_transitions_total"}, prometheus.CounterValue), strings.NewReader(expected))
+	assert.NoError(t, err)
+
+	expected = `
+# HELP furiosa_npu_liveness_last_change_timestamp_seconds 
+# TYPE furiosa_npu_liveness_last_change_timestamp_seconds gauge
+furiosa_npu_liveness_last_change_timestamp_seconds{arch="rngd",core="0-7",device="npu0",uuid="uuid"} 1013
+`
+	err = testutil.CollectAndCompare(NewLabelFilterCollector(collector.lastChangeGaugeVec, prometheus.Opts{Name: "furiosa_npu_liveness_last_change_timestamp_seconds"}, prometheus.GaugeValue), strings.NewReader(expected))
+	assert.NoError(t, err)
+
+	expected = `
+# HELP furiosa_npu_flapping 
+# TYPE furiosa_npu_flapping gauge
+furiosa_npu_flapping{arch="rngd",core="0-7",device="npu0",uuid="uuid"} 1
+`
+	err = testutil.CollectAndCompare(NewLabelFilterCollector(collector.flappingGaugeVec, prometheus.Opts{Name: "furiosa_npu_flapping"}, prometheus.GaugeValue), strings.NewReader(expected))
+	assert.NoError(t, err)
+}
+
 func TestLivenessCollector_Collect(t *testing.T) {
 	//TODO: add testcases with device mock
 }
@@ -0,0 +1,114 @@
+package collector
+
+import (
+	"sync"
+	"time"
+)
+
+// LivenessOptions configures the liveness transition tracking.
+type LivenessOptions struct {
+	// FlappingThreshold is the number of state changes within FlappingWindow above which a device is flapping.
+	FlappingThreshold int
+	// FlappingWindow is the window in which the state changes are counted. A device that was dead or changed its
+	// state within the window is considered recently unhealthy.
+	FlappingWindow time.Duration
+	// FastInterval is the interval at which the liveness of the devices is sampled while any device is recently
+	// unhealthy. Zero disables the fast sampling.
+	FastInterval time.Duration
+}
+
+type livenessState struct {
+	alive       bool
+	lastChange  time.Time
+	lastDead    time.Time
+	transitions uint64
+	// changes keeps the timestamps of the state changes within the flapping window.
+	changes []time.Time
+}
+
+// livenessTracker tracks the liveness state transitions of each device, so that a device that blips dead between two
+// scrapes is still visible from the transition count.
+type livenessTracker struct {
+	sync.Mutex
+	now     func() time.Time
+	options LivenessOptions
+
+	// states maps uuid to the liveness state of the device.
+	states map[string]*livenessState
+}
+
+func newLivenessTracker(now func() time.Time, options LivenessOptions) *livenessTracker {
+	return &livenessTracker{
+		now:     now,
+		options: options,
+		states:  make(map[string]*livenessState),
+	}
+}
+
+// observe records the liveness of the device and returns a copy of its state. The first observation of a device
+// counts as a change at the time of the observation, but not as a transition.
+func (l *livenessTracker) observe(uuid string, alive bool) livenessState {
+	l.Lock()
+	defer l.Unlock()
+
+	now := l.now()
+	state, found := l.states[uuid]
+	if !found {
+		state = &livenessState{alive: alive, lastChange: now}
+		l.states[uuid] = state
+	} else if state.alive != alive {
+		state.alive = alive
+		state.lastChange = now
+		state.transitions++
+		state.changes = append(state.changes, now)
+	}
+
+	if !alive {
+		state.lastDead = now
+	}
+
+	state.changes = l.pruneChanges(state.changes, now)
+
+	return livenessState{
+		alive:       state.alive,
+		lastChange:  state.lastChange,
+		lastDead:    state.lastDead,
+		transitions: state.transitions,
+		changes:     append([]time.Time(nil), state.changes...),
+	}
+}
+
+// isFlapping returns whether the device changed its state more than the threshold within the window.
+func (l *livenessTracker) isFlapping(state livenessState) bool {
+	return len(state.changes) > l.options.FlappingThreshold
+}
+
+// recentlyUnhealthy returns whether any device is dead or was dead within the flapping window.
+func (l *livenessTracker) recentlyUnhealthy() bool {
+	l.Lock()
+	defer l.Unlock()
+
+	now := l.now()
+	for _, state := range l.states {
+		if !state.alive {
+			return true
+		}
+
+		if !state.lastDead.IsZero() && now.Sub(state.lastDead) <= l.options.FlappingWindow {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (l *livenessTracker) pruneChanges(changes []time.Time, now time.Time) []time.Time {
+	pruned := changes[:0]
+	for _, change := range changes {
+		if now.Sub(change) <= l.options.FlappingWindow {
+			pruned = append(pruned, change)
+		}
+	}
+
+	return pruned
+}
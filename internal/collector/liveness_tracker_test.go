@@ -0,0 +1,51 @@
+package collector
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLivenessTracker_Observe(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	tracker := newLivenessTracker(clock.Now, LivenessOptions{FlappingThreshold: 2, FlappingWindow: 60 * time.Second})
+
+	// the first observation is not a transition
+	state := tracker.observe("uuid", true)
+	assert.Equal(t, uint64(0), state.transitions)
+	assert.Equal(t, time.Unix(1000, 0), state.lastChange)
+	assert.False(t, tracker.isFlapping(state))
+	assert.False(t, tracker.recentlyUnhealthy())
+
+	// the device blips dead and comes back
+	clock.Advance(10 * time.Second)
+	state = tracker.observe("uuid", false)
+	assert.Equal(t, uint64(1), state.transitions)
+	assert.True(t, tracker.recentlyUnhealthy())
+
+	clock.Advance(time.Second)
+	state = tracker.observe("uuid", true)
+	assert.Equal(t, uint64(2), state.transitions)
+	assert.Equal(t, time.Unix(1011, 0), state.lastChange)
+	assert.False(t, tracker.isFlapping(state))
+	assert.True(t, tracker.recentlyUnhealthy())
+
+	// the third change within the window makes the device flapping
+	clock.Advance(time.Second)
+	state = tracker.observe("uuid", false)
+	assert.Equal(t, uint64(3), state.transitions)
+	assert.True(t, tracker.isFlapping(state))
+
+	clock.Advance(time.Second)
+	state = tracker.observe("uuid", true)
+	assert.True(t, tracker.isFlapping(state))
+
+	// the changes age out of the window, while the transitions keep accumulating
+	clock.Advance(61 * time.Second)
+	state = tracker.observe("uuid", true)
+	assert.Equal(t, uint64(4), state.transitions)
+	assert.Equal(t, time.Unix(1013, 0), state.lastChange)
+	assert.False(t, tracker.isFlapping(state))
+	assert.False(t, tracker.recentlyUnhealthy())
+}
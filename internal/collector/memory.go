@@ -1 +1,90 @@
 package collector
+
+import (
+	"errors"
+
+	"github.com/furiosa-ai/furiosa-smi-go/pkg/smi"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	memoryFrequency = "memoryFrequency"
+)
+
+type memoryFrequencyCollector struct {
+	devices       []smi.Device
+	metricFactory MetricFactory
+	gaugeVec      *prometheus.GaugeVec
+	kubeResMapper KubeResourcesMapper
+}
+
+var _ Collector = (*memoryFrequencyCollector)(nil)
+
+func NewMemoryFrequencyCollector(devices []smi.Device, metricFactory MetricFactory, kubeResMapper KubeResourcesMapper) Collector {
+	return &memoryFrequencyCollector{
+		devices:       devices,
+		metricFactory: metricFactory,
+		kubeResMapper: kubeResMapper,
+	}
+}
+
+func (t *memoryFrequencyCollector) Register() {
+	opts := prometheus.GaugeOpts{
+		Name: "furiosa_npu_memory_frequency",
+		Help: "The current memory frequency of NPU device (MHz)",
+	}
+
+	t.gaugeVec = prometheus.NewGaugeVec(opts, defaultMetricLabels(t.kubeResMapper.MetricLabels()...))
+
+	prometheus.MustRegister(NewLabelFilterCollector(
+		t.gaugeVec,
+		prometheus.Opts(opts),
+		prometheus.GaugeValue,
+	))
+}
+
+func (t *memoryFrequencyCollector) Collect() error {
+	metricContainer := make(MetricContainer, 0, len(t.devices))
+
+	errs := make([]error, 0)
+	for _, d := range t.devices {
+		metric, err := t.metricFactory.NewDeviceWiseMetric(d)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		frequency, err := d.MemoryFrequency()
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		metric[memoryFrequency] = frequency.Frequency()
+		metricContainer = append(metricContainer, metric)
+	}
+
+	if err := t.postProcess(metricContainer); err != nil {
+		errs = append(errs, err)
+	}
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	return nil
+}
+
+func (t *memoryFrequencyCollector) postProcess(metrics MetricContainer) error {
+	labelNames := defaultMetricLabels(t.kubeResMapper.MetricLabels()...)
+	transformed := t.kubeResMapper.TransformDeviceMetrics(metrics, false)
+	t.gaugeVec.Reset()
+
+	for _, metric := range transformed {
+		if value, ok := metric[memoryFrequency]; ok {
+			t.gaugeVec.With(metricLabels(labelNames, metric, nil)).Set(float64(value.(uint32)))
+		}
+	}
+
+	return nil
+}
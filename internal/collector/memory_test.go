@@ -0,0 +1,81 @@
+package collector
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/furiosa-ai/furiosa-smi-go/pkg/smi"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func newFakeMemoryFrequencyCollector() Collector {
+	return &memoryFrequencyCollector{
+		devices:       nil,
+		metricFactory: nil,
+		kubeResMapper: NewFakeKubeResourcesMapper(),
+	}
+}
+
+func TestMemoryFrequencyCollector_PostProcessing(t *testing.T) {
+	tests := []struct {
+		description string
+		source      MetricContainer
+		expected    string
+	}{
+		{
+			description: "random memory frequency metrics",
+			source: func() MetricContainer {
+				tc := MetricContainer{}
+				metric := newMetric()
+				metric[arch] = "rngd"
+				metric[core] = "0-7"
+				metric[device] = "npu0"
+				metric[uuid] = "uuid"
+				metric[bdf] = "bdf"
+				metric[memoryFrequency] = uint32(6000)
+				tc = append(tc, metric)
+				return tc
+			}(),
+			expected: `
+# HELP furiosa_npu_memory_frequency The current memory frequency of NPU device (MHz)
+# TYPE furiosa_npu_memory_frequency gauge
+furiosa_npu_memory_frequency{arch="rngd",core="0-7",device="npu0",pci_bus_id="bdf",uuid="uuid"} 6000
+`,
+		},
+	}
+
+	collector := newFakeMemoryFrequencyCollector()
+	useTestRegistry(t)
+	collector.Register()
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			err := collector.postProcess(tc.source)
+			assert.NoError(t, err)
+
+			err = testutil.GatherAndCompare(prometheus.DefaultGatherer, strings.NewReader(head+tc.expected), "furiosa_npu_memory_frequency")
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestMemoryFrequencyCollector_Collect(t *testing.T) {
+	devices := []smi.Device{smi.GetStaticMockDevice(smi.ArchRngd, 0)}
+
+	collector := NewMemoryFrequencyCollector(devices, NewMetricFactory("", "", false), NewFakeKubeResourcesMapper())
+	useTestRegistry(t)
+	collector.Register()
+
+	err := collector.Collect()
+	assert.NoError(t, err)
+
+	expected := `
+# HELP furiosa_npu_memory_frequency The current memory frequency of NPU device (MHz)
+# TYPE furiosa_npu_memory_frequency gauge
+furiosa_npu_memory_frequency{arch="rngd",core="0-7",device="npu0",firmware_version="1.6.0+c1bebfd",pci_bus_id="0000:27:00.0",pert_version="0.0.0+",uuid="A76AAD68-6855-40B1-9E86-D080852D1C80"} 6000
+`
+	err = testutil.GatherAndCompare(prometheus.DefaultGatherer, strings.NewReader(expected), "furiosa_npu_memory_frequency")
+	assert.NoError(t, err)
+}
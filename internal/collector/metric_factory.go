@@ -3,28 +3,55 @@ package collector
 import (
 	"fmt"
 	"github.com/furiosa-ai/furiosa-smi-go/pkg/smi"
+	"github.com/prometheus/client_golang/prometheus"
 	"slices"
+	"strconv"
+	"strings"
 )
 
 type MetricFactory interface {
 	NewDeviceWiseMetric(d smi.Device) (Metric, error)
+	// NewInfoMetric creates a device wise metric carrying all the device labels regardless of the labeling mode.
+	NewInfoMetric(d smi.Device) (Metric, error)
 }
 
 var _ MetricFactory = (*metricFactory)(nil)
 
-func NewMetricFactory(nodeName, driverVersion string) MetricFactory {
+// NewMetricFactory creates a MetricFactory. If infoMetricLabels is set, the version, BDF and hostname labels are left
+// empty in device wise metrics, so that they are only carried by the info metric.
+func NewMetricFactory(nodeName, driverVersion string, infoMetricLabels bool) MetricFactory {
 	return &metricFactory{
-		nodeName:      nodeName,
-		driverVersion: driverVersion,
+		nodeName:         nodeName,
+		driverVersion:    driverVersion,
+		infoMetricLabels: infoMetricLabels,
 	}
 }
 
 type metricFactory struct {
-	nodeName      string
-	driverVersion string
+	nodeName         string
+	driverVersion    string
+	infoMetricLabels bool
 }
 
 func (m *metricFactory) NewDeviceWiseMetric(d smi.Device) (Metric, error) {
+	metric, err := m.NewInfoMetric(d)
+	if err != nil {
+		return nil, err
+	}
+
+	if m.infoMetricLabels {
+		// empty labels are dropped by LabelFilterCollector
+		metric[bdf] = ""
+		metric[firmwareVersion] = ""
+		metric[pertVersion] = ""
+		metric[driverVersion] = ""
+		metric[hostname] = ""
+	}
+
+	return metric, nil
+}
+
+func (m *metricFactory) NewInfoMetric(d smi.Device) (Metric, error) {
 	metric := newMetric()
 	info, err := getDeviceInfo(d)
 	if err != nil {
@@ -45,16 +72,29 @@ func (m *metricFactory) NewDeviceWiseMetric(d smi.Device) (Metric, error) {
 }
 
 type deviceInfo struct {
+	index           uint32
 	arch            string
 	device          string
 	uuid            string
+	serial          string
+	numaNode        uint32
+	coreNum         uint32
+	major           uint16
+	minor           uint16
 	cores           []uint32
 	coreLabel       string
+	deviceFiles     []deviceFileInfo
 	bdf             string
 	firmwareVersion string
 	pertVersion     string
 }
 
+type deviceFileInfo struct {
+	path      string
+	cores     []uint32
+	coreLabel string
+}
+
 func getDeviceInfo(device smi.Device) (*deviceInfo, error) {
 	info, err := device.DeviceInfo()
 	if err != nil {
@@ -67,10 +107,17 @@ func getDeviceInfo(device smi.Device) (*deviceInfo, error) {
 	}
 
 	accumulatedCores := map[uint32]uint32{}
+	deviceFiles := make([]deviceFileInfo, 0, len(files))
 	for _, file := range files {
 		for _, c := range file.Cores() {
 			accumulatedCores[c] = c
 		}
+
+		deviceFiles = append(deviceFiles, deviceFileInfo{
+			path:      file.Path(),
+			cores:     file.Cores(),
+			coreLabel: formatCoreLabel(file.Cores()),
+		})
 	}
 
 	cores := make([]uint32, 0, len(accumulatedCores))
@@ -78,30 +125,76 @@ func getDeviceInfo(device smi.Device) (*deviceInfo, error) {
 		cores = append(cores, c)
 	}
 
-	start := slices.Min(cores)
-	end := slices.Max(cores)
-
-	var coreLabel string
-	if start == end {
-		coreLabel = fmt.Sprintf("%d", start)
-	} else {
-		coreLabel = fmt.Sprintf("%d-%d", start, end)
-	}
-
 	return &deviceInfo{
+		index:           info.Index(),
 		arch:            info.Arch().ToString(),
 		device:          info.Name(),
 		uuid:            info.UUID(),
+		serial:          info.Serial(),
+		numaNode:        info.NumaNode(),
+		coreNum:         info.CoreNum(),
+		major:           info.Major(),
+		minor:           info.Minor(),
 		cores:           cores,
-		coreLabel:       coreLabel,
+		coreLabel:       formatCoreLabel(cores),
+		deviceFiles:     deviceFiles,
 		bdf:             info.BDF(),
 		firmwareVersion: info.FirmwareVersion().String(),
 		pertVersion:     info.PertVersion().String(),
 	}, nil
 }
 
-func defaultMetricLabels() []string {
-	return []string{
+// formatCoreLabel formats the given cores as a core label such as "0" or "0-7".
+func formatCoreLabel(cores []uint32) string {
+	if len(cores) == 0 {
+		return ""
+	}
+
+	start := slices.Min(cores)
+	end := slices.Max(cores)
+
+	if start == end {
+		return fmt.Sprintf("%d", start)
+	}
+
+	return fmt.Sprintf("%d-%d", start, end)
+}
+
+// coreCount returns the number of cores denoted by a core label such as "0" or "0-3".
+func coreCount(coreLabel string) int {
+	return len(parseCoreLabel(coreLabel))
+}
+
+// parseCoreLabel returns the cores denoted by a core label such as "0" or "0-3", or nil if the label is malformed.
+func parseCoreLabel(coreLabel string) []int {
+	coreRange := strings.Split(coreLabel, "-")
+
+	start, err := strconv.Atoi(coreRange[0])
+	if err != nil {
+		return nil
+	}
+
+	if len(coreRange) == 1 {
+		return []int{start}
+	}
+
+	end, err := strconv.Atoi(coreRange[1])
+	if err != nil || end < start {
+		return nil
+	}
+
+	cores := make([]int, 0, end-start+1)
+	for i := start; i <= end; i++ {
+		cores = append(cores, i)
+	}
+
+	return cores
+}
+
+// defaultMetricLabels returns the labels of the device wise metrics, followed by the given labels added by the
+// resources mapper.
+func defaultMetricLabels(mapperLabels ...string) []string {
+	labels := []string{
 		arch,
 		core,
 		device,
@@ -114,7 +207,16 @@ func defaultMetricLabels() []string {
 		kubernetesNamespace,
 		kubernetesPod,
 		kubernetesContainer,
+		kubernetesPodUID,
+		containerID,
+		kubernetesTopology,
+		claimName,
+		claimNamespace,
+		ownerKind,
+		ownerName,
 	}
+
+	return append(labels, mapperLabels...)
 }
 
 func newMetric() Metric {
@@ -137,3 +239,17 @@ func deepCopyMetric(origin Metric) Metric {
 	}
 	return dst
 }
+
+// metricLabels returns the given labels of the metric merged with the given extra labels.
+func metricLabels(labelNames []string, metric Metric, extra prometheus.Labels) prometheus.Labels {
+	labels := make(prometheus.Labels, len(labelNames)+len(extra))
+	for _, l := range labelNames {
+		value, _ := metric[l].(string)
+		labels[l] = value
+	}
+	for k, v := range extra {
+		labels[k] = v
+	}
+
+	return labels
+}
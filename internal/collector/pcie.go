@@ -0,0 +1,316 @@
+package collector
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/furiosa-ai/furiosa-smi-go/pkg/smi"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	currentLinkSpeed  = "current_link_speed"
+	maxLinkSpeed      = "max_link_speed"
+	currentLinkWidth  = "current_link_width"
+	maxLinkWidth      = "max_link_width"
+	aerDevCorrectable = "aer_dev_correctable"
+	aerDevNonFatal    = "aer_dev_nonfatal"
+	aerDevFatal       = "aer_dev_fatal"
+	linkDegraded      = "linkDegraded"
+)
+
+// pcieLinkLabels maps the link attributes under `<sysfs root>/bus/pci/devices/<bdf>/` to the metric label values.
+var pcieLinkLabels = map[string]string{
+	currentLinkSpeed: "current",
+	maxLinkSpeed:     "max",
+	currentLinkWidth: "current",
+	maxLinkWidth:     "max",
+}
+
+// pcieAERLabels maps the AER counter attributes under `<sysfs root>/bus/pci/devices/<bdf>/` to the metric label values.
+var pcieAERLabels = map[string]string{
+	aerDevCorrectable: "correctable",
+	aerDevNonFatal:    "nonfatal",
+	aerDevFatal:       "fatal",
+}
+
+type pcieCollector struct {
+	devices       []smi.Device
+	metricFactory MetricFactory
+	kubeResMapper KubeResourcesMapper
+	sysfsRoot     string
+
+	linkSpeedGaugeVec    *prometheus.GaugeVec
+	linkWidthGaugeVec    *prometheus.GaugeVec
+	linkDegradedGaugeVec *prometheus.GaugeVec
+	aerErrorCounterVec   *prometheus.CounterVec
+}
+
+var _ Collector = (*pcieCollector)(nil)
+
+func NewPCIeCollector(devices []smi.Device, metricFactory MetricFactory, kubeResMapper KubeResourcesMapper, sysfsRoot string) Collector {
+	return &pcieCollector{
+		devices:       devices,
+		metricFactory: metricFactory,
+		kubeResMapper: kubeResMapper,
+		sysfsRoot:     sysfsRoot,
+	}
+}
+
+func (t *pcieCollector) Register() {
+	linkSpeedOpts := prometheus.GaugeOpts{
+		Name: "furiosa_npu_pcie_link_speed_gts",
+		Help: "The current and maximum PCIe link speed of NPU device (GT/s)",
+	}
+
+	t.linkSpeedGaugeVec = prometheus.NewGaugeVec(linkSpeedOpts, append(defaultMetricLabels(t.kubeResMapper.MetricLabels()...), label))
+
+	prometheus.MustRegister(NewLabelFilterCollector(
+		t.linkSpeedGaugeVec,
+		prometheus.Opts(linkSpeedOpts),
+		prometheus.GaugeValue,
+	))
+
+	linkWidthOpts := prometheus.GaugeOpts{
+		Name: "furiosa_npu_pcie_link_width",
+		Help: "The current and maximum PCIe link width (number of lanes) of NPU device",
+	}
+
+	t.linkWidthGaugeVec = prometheus.NewGaugeVec(linkWidthOpts, append(defaultMetricLabels(t.kubeResMapper.MetricLabels()...), label))
+
+	prometheus.MustRegister(NewLabelFilterCollector(
+		t.linkWidthGaugeVec,
+		prometheus.Opts(linkWidthOpts),
+		prometheus.GaugeValue,
+	))
+
+	linkDegradedOpts := prometheus.GaugeOpts{
+		Name: "furiosa_npu_pcie_link_degraded",
+		Help: "Whether the PCIe link of NPU device is trained at a lower speed or width than its maximum",
+	}
+
+	t.linkDegradedGaugeVec = prometheus.NewGaugeVec(linkDegradedOpts, defaultMetricLabels(t.kubeResMapper.MetricLabels()...))
+
+	prometheus.MustRegister(NewLabelFilterCollector(
+		t.linkDegradedGaugeVec,
+		prometheus.Opts(linkDegradedOpts),
+		prometheus.GaugeValue,
+	))
+
+	aerErrorOpts := prometheus.CounterOpts{
+		Name: "furiosa_npu_pcie_aer_errors_total",
+		Help: "The PCIe AER error count of NPU device by severity",
+	}
+
+	t.aerErrorCounterVec = prometheus.NewCounterVec(aerErrorOpts, append(defaultMetricLabels(t.kubeResMapper.MetricLabels()...), label))
+
+	prometheus.MustRegister(NewLabelFilterCollector(
+		t.aerErrorCounterVec,
+		prometheus.Opts(aerErrorOpts),
+		prometheus.CounterValue,
+	))
+}
+
+func (t *pcieCollector) Collect() error {
+	metricContainer := make(MetricContainer, 0, len(t.devices))
+
+	errs := make([]error, 0)
+	for _, d := range t.devices {
+		metric, err := t.metricFactory.NewDeviceWiseMetric(d)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		// the bdf label may be left empty by the metric factory, so read it from the device
+		info, err := d.DeviceInfo()
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		attributes, err := readPCIeAttributes(t.sysfsRoot, info.BDF())
+		if err != nil {
+			errs = append(errs, err)
+		}
+
+		if len(attributes) == 0 {
+			continue
+		}
+
+		for attribute, value := range attributes {
+			metric[attribute] = value
+		}
+
+		if degraded, ok := isLinkDegraded(attributes); ok {
+			metric[linkDegraded] = degraded
+		}
+
+		metricContainer = append(metricContainer, metric)
+	}
+
+	if err := t.postProcess(metricContainer); err != nil {
+		errs = append(errs, err)
+	}
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	return nil
+}
+
+func (t *pcieCollector) postProcess(metrics MetricContainer) error {
+	labelNames := defaultMetricLabels(t.kubeResMapper.MetricLabels()...)
+	transformed := t.kubeResMapper.TransformDeviceMetrics(metrics, false)
+	t.linkSpeedGaugeVec.Reset()
+	t.linkWidthGaugeVec.Reset()
+	t.linkDegradedGaugeVec.Reset()
+	t.aerErrorCounterVec.Reset()
+
+	for _, metric := range transformed {
+		for _, attribute := range []string{currentLinkSpeed, maxLinkSpeed} {
+			if value, ok := metric[attribute]; ok {
+				t.linkSpeedGaugeVec.With(metricLabels(labelNames, metric, prometheus.Labels{label: pcieLinkLabels[attribute]})).Set(value.(float64))
+			}
+		}
+
+		for _, attribute := range []string{currentLinkWidth, maxLinkWidth} {
+			if value, ok := metric[attribute]; ok {
+				t.linkWidthGaugeVec.With(metricLabels(labelNames, metric, prometheus.Labels{label: pcieLinkLabels[attribute]})).Set(value.(float64))
+			}
+		}
+
+		if value, ok := metric[linkDegraded]; ok {
+			t.linkDegradedGaugeVec.With(metricLabels(labelNames, metric, nil)).Set(value.(float64))
+		}
+
+		for _, attribute := range []string{aerDevCorrectable, aerDevNonFatal, aerDevFatal} {
+			if value, ok := metric[attribute]; ok {
+				t.aerErrorCounterVec.With(metricLabels(labelNames, metric, prometheus.Labels{label: pcieAERLabels[attribute]})).Add(value.(float64))
+			}
+		}
+	}
+
+	return nil
+}
+
+// isLinkDegraded returns 1 if the current link speed or width is lower than the maximum. It returns false if neither
+// the speed nor the width pair is available.
+func isLinkDegraded(attributes map[string]float64) (float64, bool) {
+	found := false
+	degraded := 0.0
+	for _, pair := range [][2]string{{currentLinkSpeed, maxLinkSpeed}, {currentLinkWidth, maxLinkWidth}} {
+		current, currentFound := attributes[pair[0]]
+		maximum, maxFound := attributes[pair[1]]
+		if !currentFound || !maxFound {
+			continue
+		}
+
+		found = true
+		if current < maximum {
+			degraded = 1
+		}
+	}
+
+	return degraded, found
+}
+
+// readPCIeAttributes reads the link and AER attributes of the given PCI device from sysfs. Missing attributes, and link
+// speeds reported as unknown, are skipped.
+func readPCIeAttributes(sysfsRoot string, bdfValue string) (map[string]float64, error) {
+	dir := filepath.Join(sysfsRoot, "bus", "pci", "devices", bdfValue)
+
+	attributes := make(map[string]float64, len(pcieLinkLabels)+len(pcieAERLabels))
+	errs := make([]error, 0)
+	for _, attribute := range []string{currentLinkSpeed, maxLinkSpeed, currentLinkWidth, maxLinkWidth, aerDevCorrectable, aerDevNonFatal, aerDevFatal} {
+		raw, err := os.ReadFile(filepath.Join(dir, attribute))
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		var value float64
+		var ok bool
+		switch attribute {
+		case currentLinkSpeed, maxLinkSpeed:
+			value, ok, err = parseLinkSpeed(string(raw))
+		case currentLinkWidth, maxLinkWidth:
+			value, ok, err = parseLinkWidth(string(raw))
+		default:
+			value, ok, err = parseAERCounter(string(raw))
+		}
+
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to parse '%s' of %s: %w", attribute, bdfValue, err))
+			continue
+		}
+
+		if ok {
+			attributes[attribute] = value
+		}
+	}
+
+	if len(errs) > 0 {
+		return attributes, errors.Join(errs...)
+	}
+
+	return attributes, nil
+}
+
+// parseLinkSpeed parses a link speed such as "16.0 GT/s PCIe" or "8 GT/s". The speed is not available if reported as
+// "Unknown".
+func parseLinkSpeed(raw string) (float64, bool, error) {
+	fields := strings.Fields(raw)
+	if len(fields) == 0 || strings.HasPrefix(fields[0], "Unknown") {
+		return 0, false, nil
+	}
+
+	value, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return 0, false, err
+	}
+
+	return value, true, nil
+}
+
+// parseLinkWidth parses a link width such as "16" or "x16".
+func parseLinkWidth(raw string) (float64, bool, error) {
+	value, err := strconv.ParseUint(strings.TrimPrefix(strings.TrimSpace(raw), "x"), 10, 32)
+	if err != nil {
+		return 0, false, err
+	}
+
+	return float64(value), true, nil
+}
+
+// parseAERCounter parses an AER counter file consisting of "<error> <count>" lines, returning the `TOTAL_ERR_*` line if
+// present, otherwise the sum of the counts.
+func parseAERCounter(raw string) (float64, bool, error) {
+	sum := uint64(0)
+	for _, line := range strings.Split(raw, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+
+		count, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			return 0, false, err
+		}
+
+		if strings.HasPrefix(fields[0], "TOTAL_ERR_") {
+			return float64(count), true, nil
+		}
+
+		sum += count
+	}
+
+	return float64(sum), true, nil
+}
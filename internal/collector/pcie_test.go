@@ -0,0 +1,88 @@
+package collector
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/furiosa-ai/furiosa-smi-go/pkg/smi"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func writeFakePCIeAttributes(t *testing.T, sysfsRoot string, bdfValue string, attributes map[string]string) {
+	dir := filepath.Join(sysfsRoot, "bus", "pci", "devices", bdfValue)
+	assert.NoError(t, os.MkdirAll(dir, 0755))
+
+	for attribute, value := range attributes {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, attribute), []byte(value+"\n"), 0644))
+	}
+}
+
+func TestPCIeCollector_Collect(t *testing.T) {
+	sysfsRoot := t.TempDir()
+	devices := []smi.Device{smi.GetStaticMockDevice(smi.ArchRngd, 0)}
+
+	collector := NewPCIeCollector(devices, NewMetricFactory("", "", false), NewFakeKubeResourcesMapper(), sysfsRoot)
+	useTestRegistry(t)
+	collector.Register()
+
+	// the link is trained at a reduced width
+	writeFakePCIeAttributes(t, sysfsRoot, "0000:27:00.0", map[string]string{
+		"current_link_speed":  "32.0 GT/s PCIe",
+		"max_link_speed":      "32.0 GT/s PCIe",
+		"current_link_width":  "8",
+		"max_link_width":      "16",
+		"aer_dev_correctable": "RxErr 2\nBadTLP 1\nBadDLLP 0\nTOTAL_ERR_COR 3",
+		"aer_dev_nonfatal":    "Undefined 0\nDLP 0\nTOTAL_ERR_NONFATAL 0",
+		"aer_dev_fatal":       "Undefined 0\nDLP 0\nTOTAL_ERR_FATAL 0",
+	})
+	err := collector.Collect()
+	assert.NoError(t, err)
+
+	expected := `
+# HELP furiosa_npu_pcie_aer_errors_total The PCIe AER error count of NPU device by severity
+# TYPE furiosa_npu_pcie_aer_errors_total counter
+furiosa_npu_pcie_aer_errors_total{arch="rngd",core="0-7",device="npu0",firmware_version="1.6.0+c1bebfd",label="correctable",pci_bus_id="0000:27:00.0",pert_version="0.0.0+",uuid="A76AAD68-6855-40B1-9E86-D080852D1C80"} 3
+furiosa_npu_pcie_aer_errors_total{arch="rngd",core="0-7",device="npu0",firmware_version="1.6.0+c1bebfd",label="fatal",pci_bus_id="0000:27:00.0",pert_version="0.0.0+",uuid="A76AAD68-6855-40B1-9E86-D080852D1C80"} 0
+furiosa_npu_pcie_aer_errors_total{arch="rngd",core="0-7",device="npu0",firmware_version="1.6.0+c1bebfd",label="nonfatal",pci_bus_id="0000:27:00.0",pert_version="0.0.0+",uuid="A76AAD68-6855-40B1-9E86-D080852D1C80"} 0
+# HELP furiosa_npu_pcie_link_degraded Whether the PCIe link of NPU device is trained at a lower speed or width than its maximum
+# TYPE furiosa_npu_pcie_link_degraded gauge
+furiosa_npu_pcie_link_degraded{arch="rngd",core="0-7",device="npu0",firmware_version="1.6.0+c1bebfd",pci_bus_id="0000:27:00.0",pert_version="0.0.0+",uuid="A76AAD68-6855-40B1-9E86-D080852D1C80"} 1
+# HELP furiosa_npu_pcie_link_speed_gts The current and maximum PCIe link speed of NPU device (GT/s)
+# TYPE furiosa_npu_pcie_link_speed_gts gauge
+furiosa_npu_pcie_link_speed_gts{arch="rngd",core="0-7",device="npu0",firmware_version="1.6.0+c1bebfd",label="current",pci_bus_id="0000:27:00.0",pert_version="0.0.0+",uuid="A76AAD68-6855-40B1-9E86-D080852D1C80"} 32
+furiosa_npu_pcie_link_speed_gts{arch="rngd",core="0-7",device="npu0",firmware_version="1.6.0+c1bebfd",label="max",pci_bus_id="0000:27:00.0",pert_version="0.0.0+",uuid="A76AAD68-6855-40B1-9E86-D080852D1C80"} 32
+# HELP furiosa_npu_pcie_link_width The current and maximum PCIe link width (number of lanes) of NPU device
+# TYPE furiosa_npu_pcie_link_width gauge
+furiosa_npu_pcie_link_width{arch="rngd",core="0-7",device="npu0",firmware_version="1.6.0+c1bebfd",label="current",pci_bus_id="0000:27:00.0",pert_version="0.0.0+",uuid="A76AAD68-6855-40B1-9E86-D080852D1C80"} 8
+furiosa_npu_pcie_link_width{arch="rngd",core="0-7",device="npu0",firmware_version="1.6.0+c1bebfd",label="max",pci_bus_id="0000:27:00.0",pert_version="0.0.0+",uuid="A76AAD68-6855-40B1-9E86-D080852D1C80"} 16
+`
+	err = testutil.GatherAndCompare(prometheus.DefaultGatherer, strings.NewReader(expected), "furiosa_npu_pcie_link_speed_gts", "furiosa_npu_pcie_link_width", "furiosa_npu_pcie_link_degraded", "furiosa_npu_pcie_aer_errors_total")
+	assert.NoError(t, err)
+}
+
+func TestReadPCIeAttributes(t *testing.T) {
+	sysfsRoot := t.TempDir()
+
+	attributes, err := readPCIeAttributes(sysfsRoot, "0000:27:00.0")
+	assert.NoError(t, err)
+	assert.Empty(t, attributes)
+
+	writeFakePCIeAttributes(t, sysfsRoot, "0000:27:00.0", map[string]string{
+		"current_link_speed": "Unknown",
+		"max_link_speed":     "16.0 GT/s PCIe",
+		"current_link_width": "x16",
+		"max_link_width":     "invalid",
+	})
+
+	attributes, err = readPCIeAttributes(sysfsRoot, "0000:27:00.0")
+	assert.Error(t, err)
+	assert.Equal(t, map[string]float64{"max_link_speed": 16, "current_link_width": 16}, attributes)
+
+	_, found := isLinkDegraded(attributes)
+	assert.False(t, found)
+}
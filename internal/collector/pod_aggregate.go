@@ -0,0 +1,338 @@
+package collector
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/furiosa-ai/furiosa-smi-go/pkg/smi"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	podPowerShare      = "podPowerShare"
+	podEnergyShare     = "podEnergyShare"
+	podUtilizationSum  = "podUtilizationSum"
+	podUtilizedCores   = "podUtilizedCores"
+	podAllocatedCores  = "podAllocatedCores"
+	podAggregateKeySep = "/"
+)
+
+// podAggregateCollector exports the power, energy and utilization of the NPU devices attributed to each pod and
+// namespace. Unlike the attributed device-wise series, which repeat the whole device value for each pod of a
+// partitioned device, the power and energy are split by the fraction of the device cores allocated to the pod, and the
+// utilization is averaged over the allocated cores, so the series can be summed without double counting. The devices
+// are not read again, the power, energy and utilization are those last read by the power and core utilization
+// collectors.
+type podAggregateCollector struct {
+	devices          []smi.Device
+	metricFactory    MetricFactory
+	kubeResMapper    AllocationStateMapper
+	samples          *DeviceSamples
+	energyIntegrator *energyIntegrator
+
+	// deviceJoules keeps the total energy of each device by uuid at the previous collection.
+	deviceJoules map[string]float64
+
+	podPowerGaugeVec             *prometheus.GaugeVec
+	podEnergyCounterVec          *prometheus.CounterVec
+	podUtilizationGaugeVec       *prometheus.GaugeVec
+	podAllocatedCoresGaugeVec    *prometheus.GaugeVec
+	namespacePowerGaugeVec       *prometheus.GaugeVec
+	namespaceEnergyCounterVec    *prometheus.CounterVec
+	namespaceUtilizationGaugeVec *prometheus.GaugeVec
+	namespaceCoresGaugeVec       *prometheus.GaugeVec
+}
+
+var _ Collector = (*podAggregateCollector)(nil)
+
+func NewPodAggregateCollector(devices []smi.Device, metricFactory MetricFactory, kubeResMapper AllocationStateMapper, samples *DeviceSamples) Collector {
+	return &podAggregateCollector{
+		devices:          devices,
+		metricFactory:    metricFactory,
+		kubeResMapper:    kubeResMapper,
+		samples:          samples,
+		energyIntegrator: newEnergyIntegrator(time.Now),
+		deviceJoules:     make(map[string]float64),
+	}
+}
+
+func podAggregateLabels(metadataLabels []string) []string {
+	return append([]string{hostname, kubernetesNamespace, kubernetesPod, kubernetesPodUID, ownerKind, ownerName}, metadataLabels...)
+}
+
+func namespaceAggregateLabels() []string {
+	return []string{hostname, kubernetesNamespace}
+}
+
+func (t *podAggregateCollector) Register() {
+	podPowerOpts := prometheus.GaugeOpts{
+		Name: "furiosa_npu_pod_power_watts",
+		Help: "The power of NPU devices attributed to the pod by the fraction of the allocated cores (W)",
+	}
+
+	t.podPowerGaugeVec = prometheus.NewGaugeVec(podPowerOpts, podAggregateLabels(t.kubeResMapper.MetricLabels()))
+
+	prometheus.MustRegister(NewLabelFilterCollector(
+		t.podPowerGaugeVec,
+		prometheus.Opts(podPowerOpts),
+		prometheus.GaugeValue,
+	))
+
+	podEnergyOpts := prometheus.CounterOpts{
+		Name: "furiosa_npu_pod_energy_joules_total",
+		Help: "The accumulated energy consumption of NPU devices attributed to the pod by the fraction of the allocated cores (J)",
+	}
+
+	t.podEnergyCounterVec = prometheus.NewCounterVec(podEnergyOpts, podAggregateLabels(t.kubeResMapper.MetricLabels()))
+
+	prometheus.MustRegister(NewLabelFilterCollector(
+		t.podEnergyCounterVec,
+		prometheus.Opts(podEnergyOpts),
+		prometheus.CounterValue,
+	))
+
+	podUtilizationOpts := prometheus.GaugeOpts{
+		Name: "furiosa_npu_pod_core_utilization",
+		Help: "The average utilization of the NPU cores allocated to the pod (%)",
+	}
+
+	t.podUtilizationGaugeVec = prometheus.NewGaugeVec(podUtilizationOpts, podAggregateLabels(t.kubeResMapper.MetricLabels()))
+
+	prometheus.MustRegister(NewLabelFilterCollector(
+		t.podUtilizationGaugeVec,
+		prometheus.Opts(podUtilizationOpts),
+		prometheus.GaugeValue,
+	))
+
+	podAllocatedCoresOpts := prometheus.GaugeOpts{
+		Name: "furiosa_npu_pod_allocated_cores",
+		Help: "The number of NPU cores allocated to the pod",
+	}
+
+	t.podAllocatedCoresGaugeVec = prometheus.NewGaugeVec(podAllocatedCoresOpts, podAggregateLabels(t.kubeResMapper.MetricLabels()))
+
+	prometheus.MustRegister(NewLabelFilterCollector(
+		t.podAllocatedCoresGaugeVec,
+		prometheus.Opts(podAllocatedCoresOpts),
+		prometheus.GaugeValue,
+	))
+
+	namespacePowerOpts := prometheus.GaugeOpts{
+		Name: "furiosa_npu_namespace_power_watts",
+		Help: "The power of NPU devices attributed to the pods of the namespace by the fraction of the allocated cores (W)",
+	}
+
+	t.namespacePowerGaugeVec = prometheus.NewGaugeVec(namespacePowerOpts, namespaceAggregateLabels())
+
+	prometheus.MustRegister(NewLabelFilterCollector(
+		t.namespacePowerGaugeVec,
+		prometheus.Opts(namespacePowerOpts),
+		prometheus.GaugeValue,
+	))
+
+	namespaceEnergyOpts := prometheus.CounterOpts{
+		Name: "furiosa_npu_namespace_energy_joules_total",
+		Help: "The accumulated energy consumption of NPU devices attributed to the pods of the namespace by the fraction of the allocated cores (J)",
+	}
+
+	t.namespaceEnergyCounterVec = prometheus.NewCounterVec(namespaceEnergyOpts, namespaceAggregateLabels())
+
+	prometheus.MustRegister(NewLabelFilterCollector(
+		t.namespaceEnergyCounterVec,
+		prometheus.Opts(namespaceEnergyOpts),
+		prometheus.CounterValue,
+	))
+
+	namespaceUtilizationOpts := prometheus.GaugeOpts{
+		Name: "furiosa_npu_namespace_core_utilization",
+		Help: "The average utilization of the NPU cores allocated to the pods of the namespace (%)",
+	}
+
+	t.namespaceUtilizationGaugeVec = prometheus.NewGaugeVec(namespaceUtilizationOpts, namespaceAggregateLabels())
+
+	prometheus.MustRegister(NewLabelFilterCollector(
+		t.namespaceUtilizationGaugeVec,
+		prometheus.Opts(namespaceUtilizationOpts),
+		prometheus.GaugeValue,
+	))
+
+	namespaceCoresOpts := prometheus.GaugeOpts{
+		Name: "furiosa_npu_namespace_allocated_cores",
+		Help: "The number of NPU cores allocated to the pods of the namespace",
+	}
+
+	t.namespaceCoresGaugeVec = prometheus.NewGaugeVec(namespaceCoresOpts, namespaceAggregateLabels())
+
+	prometheus.MustRegister(NewLabelFilterCollector(
+		t.namespaceCoresGaugeVec,
+		prometheus.Opts(namespaceCoresOpts),
+		prometheus.GaugeValue,
+	))
+}
+
+func (t *podAggregateCollector) Collect() error {
+	metricContainer := make(MetricContainer, 0, len(t.devices))
+
+	errs := make([]error, 0)
+	for _, d := range t.devices {
+		metric, err := t.metricFactory.NewDeviceWiseMetric(d)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		// the energy of the device is followed even while it is not allocated, so that a new pod is only charged for
+		// the energy consumed since its allocation. The share of a pod is kept with a zero energy while the power is
+		// unknown, so its counter is not reset.
+		var energy float64
+		power, powerKnown := t.samples.lastPower(metric[uuid].(string))
+		if powerKnown {
+			if previous, found := t.deviceJoules[metric[uuid].(string)]; found && power.joules > previous {
+				energy = power.joules - previous
+			}
+			t.deviceJoules[metric[uuid].(string)] = power.joules
+		}
+
+		podInfoSlice := t.kubeResMapper.allocatedPods(metric[uuid].(string))
+		if len(podInfoSlice) == 0 {
+			continue
+		}
+
+		utilizations := t.samples.lastUtilizations(metric[uuid].(string))
+
+		for _, podInformation := range podInfoSlice {
+			share := coreShare(podInformation.CoreLabel, metric[core].(string))
+
+			attributed := withPodLabels(metric, podInformation, true)
+			attributed[podEnergyShare] = energy * share
+			attributed[podAllocatedCores] = float64(len(podInformation.AllocatedPE))
+			if powerKnown {
+				attributed[podPowerShare] = power.watts * share
+			}
+
+			utilizationSum, utilizedCores := 0.0, 0
+			for _, coreIdx := range podInformation.AllocatedPE {
+				if value, ok := utilizations[coreIdx]; ok {
+					utilizationSum += value
+					utilizedCores++
+				}
+			}
+			attributed[podUtilizationSum] = utilizationSum
+			attributed[podUtilizedCores] = float64(utilizedCores)
+
+			metricContainer = append(metricContainer, attributed)
+		}
+	}
+
+	if err := t.postProcess(metricContainer); err != nil {
+		errs = append(errs, err)
+	}
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	return nil
+}
+
+// podAggregate is the sum of the shares of the devices allocated to a pod or a namespace.
+type podAggregate struct {
+	labels         prometheus.Labels
+	power          float64
+	powerKnown     bool
+	energy         float64
+	utilizationSum float64
+	utilizedCores  float64
+	allocatedCores float64
+}
+
+func (a *podAggregate) add(metric Metric) {
+	if value, ok := metric[podPowerShare]; ok {
+		a.power += value.(float64)
+		a.powerKnown = true
+	}
+
+	a.energy += metric[podEnergyShare].(float64)
+	a.utilizationSum += metric[podUtilizationSum].(float64)
+	a.utilizedCores += metric[podUtilizedCores].(float64)
+	a.allocatedCores += metric[podAllocatedCores].(float64)
+}
+
+// postProcess sums the shares of the devices, which are already attributed to the pods, by pod and by namespace.
+func (t *podAggregateCollector) postProcess(metrics MetricContainer) error {
+	pods := make(map[string]*podAggregate)
+	namespaces := make(map[string]*podAggregate)
+
+	for _, metric := range metrics {
+		podLabels := prometheus.Labels{}
+		for _, name := range podAggregateLabels(t.kubeResMapper.MetricLabels()) {
+			value, _ := metric[name].(string)
+			podLabels[name] = value
+		}
+
+		podKey := strings.Join([]string{podLabels[kubernetesNamespace], podLabels[kubernetesPod], podLabels[kubernetesPodUID]}, podAggregateKeySep)
+		if _, found := pods[podKey]; !found {
+			pods[podKey] = &podAggregate{labels: podLabels}
+		}
+		pods[podKey].add(metric)
+
+		// the pods attributed by the cgroup without the pod informer have no namespace to roll up to
+		if podLabels[kubernetesNamespace] == "" {
+			continue
+		}
+
+		namespaceKey := podLabels[kubernetesNamespace]
+		if _, found := namespaces[namespaceKey]; !found {
+			namespaces[namespaceKey] = &podAggregate{labels: prometheus.Labels{
+				hostname:            podLabels[hostname],
+				kubernetesNamespace: podLabels[kubernetesNamespace],
+			}}
+		}
+		namespaces[namespaceKey].add(metric)
+	}
+
+	// the energy of pods and namespaces is accumulated from the share of each interval, and dropped once they no
+	// longer have devices allocated
+	energyDeltas := make(map[string]float64, len(pods)+len(namespaces))
+	for key, aggregate := range pods {
+		energyDeltas["pod"+podAggregateKeySep+key] = aggregate.energy
+	}
+	for key, aggregate := range namespaces {
+		energyDeltas["namespace"+podAggregateKeySep+key] = aggregate.energy
+	}
+	energies := t.energyIntegrator.accumulateShares(energyDeltas)
+
+	t.podPowerGaugeVec.Reset()
+	t.podEnergyCounterVec.Reset()
+	t.podUtilizationGaugeVec.Reset()
+	t.podAllocatedCoresGaugeVec.Reset()
+	t.namespacePowerGaugeVec.Reset()
+	t.namespaceEnergyCounterVec.Reset()
+	t.namespaceUtilizationGaugeVec.Reset()
+	t.namespaceCoresGaugeVec.Reset()
+
+	for key, aggregate := range pods {
+		if aggregate.powerKnown {
+			t.podPowerGaugeVec.With(aggregate.labels).Set(aggregate.power)
+		}
+		if aggregate.utilizedCores > 0 {
+			t.podUtilizationGaugeVec.With(aggregate.labels).Set(aggregate.utilizationSum / aggregate.utilizedCores)
+		}
+		t.podEnergyCounterVec.With(aggregate.labels).Add(energies["pod"+podAggregateKeySep+key])
+		t.podAllocatedCoresGaugeVec.With(aggregate.labels).Set(aggregate.allocatedCores)
+	}
+
+	for key, aggregate := range namespaces {
+		if aggregate.powerKnown {
+			t.namespacePowerGaugeVec.With(aggregate.labels).Set(aggregate.power)
+		}
+		if aggregate.utilizedCores > 0 {
+			t.namespaceUtilizationGaugeVec.With(aggregate.labels).Set(aggregate.utilizationSum / aggregate.utilizedCores)
+		}
+		t.namespaceEnergyCounterVec.With(aggregate.labels).Add(energies["namespace"+podAggregateKeySep+key])
+		t.namespaceCoresGaugeVec.With(aggregate.labels).Set(aggregate.allocatedCores)
+	}
+
+	return nil
+}
@@ -0,0 +1,164 @@
+package collector
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/furiosa-ai/furiosa-smi-go/pkg/smi"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPodAggregateCollector_Collect(t *testing.T) {
+	devices := []smi.Device{smi.GetStaticMockDevice(smi.ArchRngd, 0), smi.GetStaticMockDevice(smi.ArchRngd, 1)}
+	cores := newMockDeviceCores(t, smi.ArchRngd)
+
+	// npu0 is partitioned between two pods of the same namespace, and npu1 is allocated to a pod of another namespace
+	mapper := &kubeResourcesMapper{
+		enabled:     true,
+		deviceCores: cores,
+		deviceWiseCache: deviceWiseCache{
+			"A76AAD68-6855-40B1-9E86-D080852D1C80": {
+				{Name: "pod-a", Namespace: "default", ContainerName: "main", AllocatedPE: []int{0, 1, 2, 3}, CoreLabel: "0-3"},
+				{Name: "pod-b", Namespace: "default", ContainerName: "main", AllocatedPE: []int{4, 5, 6, 7}, CoreLabel: "4-7"},
+			},
+			"A76AAD68-6855-40B1-9E86-D080852D1C81": {
+				{Name: "pod-c", Namespace: "batch", ContainerName: "main", AllocatedPE: cores["A76AAD68-6855-40B1-9E86-D080852D1C81"], CoreLabel: "0-7"},
+			},
+		},
+	}
+
+	// the power and utilization are read by the power and core utilization collectors
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	samples := NewDeviceSamples()
+	powerCollector := newFakeEnergyPowerCollector(devices, mapper, clock)
+	powerCollector.samples = samples
+	utilizationCollector := NewCoreUtilizationCollector(devices, NewMetricFactory("", "", false), mapper, samples)
+
+	collector := NewPodAggregateCollector(devices, NewMetricFactory("", "", false), mapper, samples).(*podAggregateCollector)
+	collector.energyIntegrator = newEnergyIntegrator(clock.Now)
+	useTestRegistry(t)
+	utilizationCollector.Register()
+	collector.Register()
+
+	collect := func() {
+		assert.NoError(t, powerCollector.Collect())
+		assert.NoError(t, utilizationCollector.Collect())
+		assert.NoError(t, collector.Collect())
+	}
+
+	collect()
+	clock.Advance(10 * time.Second)
+	collect()
+
+	expected := `
+# HELP furiosa_npu_pod_power_watts The power of NPU devices attributed to the pod by the fraction of the allocated cores (W)
+# TYPE furiosa_npu_pod_power_watts gauge
+furiosa_npu_pod_power_watts{namespace="batch",pod="pod-c"} 100
+furiosa_npu_pod_power_watts{namespace="default",pod="pod-a"} 50
+furiosa_npu_pod_power_watts{namespace="default",pod="pod-b"} 50
+# HELP furiosa_npu_pod_energy_joules_total The accumulated energy consumption of NPU devices attributed to the pod by the fraction of the allocated cores (J)
+# TYPE furiosa_npu_pod_energy_joules_total counter
+furiosa_npu_pod_energy_joules_total{namespace="batch",pod="pod-c"} 1000
+furiosa_npu_pod_energy_joules_total{namespace="default",pod="pod-a"} 500
+furiosa_npu_pod_energy_joules_total{namespace="default",pod="pod-b"} 500
+# HELP furiosa_npu_pod_core_utilization The average utilization of the NPU cores allocated to the pod (%)
+# TYPE furiosa_npu_pod_core_utilization gauge
+furiosa_npu_pod_core_utilization{namespace="batch",pod="pod-c"} 50
+furiosa_npu_pod_core_utilization{namespace="default",pod="pod-a"} 50
+furiosa_npu_pod_core_utilization{namespace="default",pod="pod-b"} 50
+# HELP furiosa_npu_pod_allocated_cores The number of NPU cores allocated to the pod
+# TYPE furiosa_npu_pod_allocated_cores gauge
+furiosa_npu_pod_allocated_cores{namespace="batch",pod="pod-c"} 8
+furiosa_npu_pod_allocated_cores{namespace="default",pod="pod-a"} 4
+furiosa_npu_pod_allocated_cores{namespace="default",pod="pod-b"} 4
+# HELP furiosa_npu_namespace_power_watts The power of NPU devices attributed to the pods of the namespace by the fraction of the allocated cores (W)
+# TYPE furiosa_npu_namespace_power_watts gauge
+furiosa_npu_namespace_power_watts{namespace="batch"} 100
+furiosa_npu_namespace_power_watts{namespace="default"} 100
+# HELP furiosa_npu_namespace_energy_joules_total The accumulated energy consumption of NPU devices attributed to the pods of the namespace by the fraction of the allocated cores (J)
+# TYPE furiosa_npu_namespace_energy_joules_total counter
+furiosa_npu_namespace_energy_joules_total{namespace="batch"} 1000
+furiosa_npu_namespace_energy_joules_total{namespace="default"} 1000
+# HELP furiosa_npu_namespace_core_utilization The average utilization of the NPU cores allocated to the pods of the namespace (%)
+# TYPE furiosa_npu_namespace_core_utilization gauge
+furiosa_npu_namespace_core_utilization{namespace="batch"} 50
+furiosa_npu_namespace_core_utilization{namespace="default"} 50
+# HELP furiosa_npu_namespace_allocated_cores The number of NPU cores allocated to the pods of the namespace
+# TYPE furiosa_npu_namespace_allocated_cores gauge
+furiosa_npu_namespace_allocated_cores{namespace="batch"} 8
+furiosa_npu_namespace_allocated_cores{namespace="default"} 8
+`
+	err := testutil.GatherAndCompare(prometheus.DefaultGatherer, strings.NewReader(expected),
+		"furiosa_npu_pod_power_watts",
+		"furiosa_npu_pod_energy_joules_total",
+		"furiosa_npu_pod_core_utilization",
+		"furiosa_npu_pod_allocated_cores",
+		"furiosa_npu_namespace_power_watts",
+		"furiosa_npu_namespace_energy_joules_total",
+		"furiosa_npu_namespace_core_utilization",
+		"furiosa_npu_namespace_allocated_cores",
+	)
+	assert.NoError(t, err)
+
+	// the energy of a released pod is dropped, while the namespace keeps accumulating the energy of its other pods
+	mapper.deviceWiseCache["A76AAD68-6855-40B1-9E86-D080852D1C80"] = mapper.deviceWiseCache["A76AAD68-6855-40B1-9E86-D080852D1C80"][1:]
+	clock.Advance(10 * time.Second)
+	collect()
+	assert.Equal(t, 2, testutil.CollectAndCount(collector.podEnergyCounterVec))
+	assert.Equal(t, 1000.0, testutil.ToFloat64(collector.podEnergyCounterVec.WithLabelValues("", "default", "pod-b", "", "", "")))
+	assert.Equal(t, 1500.0, testutil.ToFloat64(collector.namespaceEnergyCounterVec.WithLabelValues("", "default")))
+}
+
+func TestPodAggregateCollector_UnallocatedEnergy(t *testing.T) {
+	devices := []smi.Device{smi.GetStaticMockDevice(smi.ArchRngd, 0)}
+	mapper := &kubeResourcesMapper{
+		enabled:         true,
+		deviceCores:     newMockDeviceCores(t, smi.ArchRngd),
+		deviceWiseCache: deviceWiseCache{},
+	}
+
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	samples := NewDeviceSamples()
+	powerCollector := newFakeEnergyPowerCollector(devices, mapper, clock)
+	powerCollector.samples = samples
+
+	collector := NewPodAggregateCollector(devices, NewMetricFactory("", "", false), mapper, samples).(*podAggregateCollector)
+	collector.energyIntegrator = newEnergyIntegrator(clock.Now)
+	useTestRegistry(t)
+	collector.Register()
+
+	// a newly allocated pod is only charged for the energy consumed since the previous collection
+	for range 2 {
+		assert.NoError(t, powerCollector.Collect())
+		assert.NoError(t, collector.Collect())
+		clock.Advance(10 * time.Second)
+	}
+
+	mapper.deviceWiseCache["A76AAD68-6855-40B1-9E86-D080852D1C80"] = []podInfo{
+		{Name: "pod-a", Namespace: "default", ContainerName: "main", AllocatedPE: []int{0, 1, 2, 3, 4, 5, 6, 7}, CoreLabel: "0-7"},
+	}
+	assert.NoError(t, powerCollector.Collect())
+	assert.NoError(t, collector.Collect())
+
+	assert.Equal(t, 1000.0, testutil.ToFloat64(collector.podEnergyCounterVec.WithLabelValues("", "default", "pod-a", "", "", "")))
+
+	// the pod power is unknown while the power cannot be read
+	powerCollector.devices = []smi.Device{powerErrorDevice{devices[0]}}
+	assert.Error(t, powerCollector.Collect())
+	assert.NoError(t, collector.Collect())
+	assert.Equal(t, 0, testutil.CollectAndCount(collector.podPowerGaugeVec))
+	assert.Equal(t, 1000.0, testutil.ToFloat64(collector.podEnergyCounterVec.WithLabelValues("", "default", "pod-a", "", "", "")))
+}
+
+// powerErrorDevice is a device whose power cannot be read.
+type powerErrorDevice struct {
+	smi.Device
+}
+
+func (d powerErrorDevice) PowerConsumption() (float64, error) {
+	return 0, errors.New("failed to read power consumption")
+}
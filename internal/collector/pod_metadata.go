@@ -0,0 +1,253 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+const (
+	podUIDIndex = "uid"
+	// podInformerSyncTimeout is how long the pod informer waits for the initial list of the pods at startup.
+	podInformerSyncTimeout = 30 * time.Second
+)
+
+var invalidLabelNameChars = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// PodMetadataOptions configures the pod informer enriching the attributed metrics with the pod metadata.
+type PodMetadataOptions struct {
+	// Enabled enables the pod informer watching the pods scheduled on NodeName.
+	Enabled  bool
+	NodeName string
+	// KubeConfig is the path of the kubeconfig file, or empty to use the in-cluster config.
+	KubeConfig string
+	// LabelsAllowlist and AnnotationsAllowlist are the pod labels and annotations exported as `label_<name>` and
+	// `annotation_<name>` labels, with the invalid characters of the name replaced by underscores.
+	LabelsAllowlist      []string
+	AnnotationsAllowlist []string
+	// OwnerLabels enables resolving the top level workload owning the attributed pods as the `owner_kind` and
+	// `owner_name` labels.
+	OwnerLabels bool
+}
+
+// podMetadataInformer watches the pods of the node, and resolves the metadata of the attributed pods.
+type podMetadataInformer struct {
+	informer cache.SharedIndexInformer
+	// labels and annotations map the allowlisted pod label and annotation keys to the metric labels.
+	labels      map[string]string
+	annotations map[string]string
+	// owners is nil unless the owner labels are enabled.
+	owners *podOwnerResolver
+}
+
+// newKubernetesClient creates a client of the API server from the kubeconfig file, or the in-cluster config if empty.
+func newKubernetesClient(kubeConfig string) (kubernetes.Interface, error) {
+	config, err := clientcmd.BuildConfigFromFlags("", kubeConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build kubernetes client config; err: %w", err)
+	}
+
+	return kubernetes.NewForConfig(config)
+}
+
+func newPodMetadataInformer(client kubernetes.Interface, options PodMetadataOptions) (*podMetadataInformer, error) {
+	if options.NodeName == "" {
+		return nil, fmt.Errorf("node name is required to watch the pods of the node")
+	}
+
+	listWatch := cache.NewListWatchFromClient(
+		client.CoreV1().RESTClient(),
+		"pods",
+		metav1.NamespaceAll,
+		fields.OneTermEqualSelector("spec.nodeName", options.NodeName),
+	)
+
+	informer := cache.NewSharedIndexInformer(listWatch, &corev1.Pod{}, 0, cache.Indexers{
+		cache.NamespaceIndex: cache.MetaNamespaceIndexFunc,
+		podUIDIndex: func(obj interface{}) ([]string, error) {
+			return []string{string(obj.(*corev1.Pod).UID)}, nil
+		},
+	})
+
+	// only the metadata and container statuses are used, so the rest of the pod is dropped to save memory
+	if err := informer.SetTransform(func(obj interface{}) (interface{}, error) {
+		pod, ok := obj.(*corev1.Pod)
+		if !ok {
+			return obj, nil
+		}
+
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            pod.Name,
+				Namespace:       pod.Namespace,
+				UID:             pod.UID,
+				ResourceVersion: pod.ResourceVersion,
+				Labels:          pod.Labels,
+				Annotations:     pod.Annotations,
+				OwnerReferences: pod.OwnerReferences,
+			},
+			Status: corev1.PodStatus{
+				ContainerStatuses: pod.Status.ContainerStatuses,
+			},
+		}, nil
+	}); err != nil {
+		return nil, err
+	}
+
+	podMetadata := &podMetadataInformer{
+		informer:    informer,
+		labels:      metadataLabelNames("label_", options.LabelsAllowlist),
+		annotations: metadataLabelNames("annotation_", options.AnnotationsAllowlist),
+	}
+
+	if options.OwnerLabels {
+		podMetadata.owners = newPodOwnerResolver(client, time.Now)
+	}
+
+	return podMetadata, nil
+}
+
+// metadataLabelNames maps the given keys to the metric labels prefixed by prefix. The keys that collide with a previous
+// key after replacing the invalid characters are dropped.
+func metadataLabelNames(prefix string, keys []string) map[string]string {
+	names := make(map[string]string, len(keys))
+	seen := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		name := prefix + invalidLabelNameChars.ReplaceAllString(key, "_")
+		if key == "" || seen[name] {
+			continue
+		}
+
+		seen[name] = true
+		names[key] = name
+	}
+
+	return names
+}
+
+// labelNames returns the metric labels added by the informer in the order of the allowlists.
+func (p *podMetadataInformer) labelNames(options PodMetadataOptions) []string {
+	names := make([]string, 0, len(p.labels)+len(p.annotations))
+	for _, key := range options.LabelsAllowlist {
+		if name, found := p.labels[key]; found && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+
+	for _, key := range options.AnnotationsAllowlist {
+		if name, found := p.annotations[key]; found && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// start runs the informer, and waits for the pods of the node to be listed so that the first syncs are enriched.
+func (p *podMetadataInformer) start(ctx context.Context) error {
+	go p.informer.Run(ctx.Done())
+	if p.owners != nil {
+		p.owners.start(ctx)
+	}
+
+	syncCtx, cancel := context.WithTimeout(ctx, podInformerSyncTimeout)
+	defer cancel()
+
+	if !cache.WaitForCacheSync(syncCtx.Done(), p.informer.HasSynced) {
+		return fmt.Errorf("failed to sync the pods of the node within %s", podInformerSyncTimeout)
+	}
+
+	return nil
+}
+
+// pod returns the pod of the given pod information, by namespace and name if known, otherwise by uid.
+func (p *podMetadataInformer) pod(podInformation podInfo) (*corev1.Pod, bool) {
+	if podInformation.Name != "" {
+		obj, found, err := p.informer.GetStore().GetByKey(podInformation.Namespace + "/" + podInformation.Name)
+		if err != nil || !found {
+			return nil, false
+		}
+
+		return obj.(*corev1.Pod), true
+	}
+
+	if podInformation.UID == "" {
+		return nil, false
+	}
+
+	objs, err := p.informer.GetIndexer().ByIndex(podUIDIndex, podInformation.UID)
+	if err != nil || len(objs) == 0 {
+		return nil, false
+	}
+
+	return objs[0].(*corev1.Pod), true
+}
+
+// enrich fills the pod uid, the pod and container names if unknown, the owner, and the allowlisted metadata of the pod.
+func (p *podMetadataInformer) enrich(podInformation podInfo) podInfo {
+	pod, found := p.pod(podInformation)
+	if !found {
+		return podInformation
+	}
+
+	podInformation.Name = pod.Name
+	podInformation.Namespace = pod.Namespace
+	podInformation.UID = string(pod.UID)
+
+	if podInformation.ContainerName == "" && podInformation.ContainerID != "" {
+		for _, status := range pod.Status.ContainerStatuses {
+			// the container id of the status is prefixed by the runtime such as `containerd://`
+			if strings.HasSuffix(status.ContainerID, "://"+podInformation.ContainerID) {
+				podInformation.ContainerName = status.Name
+				break
+			}
+		}
+	}
+
+	if p.owners != nil {
+		podInformation.OwnerKind, podInformation.OwnerName = p.owners.resolve(pod)
+	}
+
+	if len(p.labels) == 0 && len(p.annotations) == 0 {
+		return podInformation
+	}
+
+	podInformation.Metadata = make(map[string]string, len(p.labels)+len(p.annotations))
+	for key, name := range p.labels {
+		podInformation.Metadata[name] = pod.Labels[key]
+	}
+	for key, name := range p.annotations {
+		podInformation.Metadata[name] = pod.Annotations[key]
+	}
+
+	return podInformation
+}
+
+// enrichCache enriches the pod information of the device and core wise caches in place.
+func (p *podMetadataInformer) enrichCache(deviceWise deviceWiseCache, coreWise coreWiseCache) {
+	for uuidValue, podInfoSlice := range deviceWise {
+		enriched := make([]podInfo, 0, len(podInfoSlice))
+		for _, podInformation := range podInfoSlice {
+			enriched = append(enriched, p.enrich(podInformation))
+		}
+		deviceWise[uuidValue] = enriched
+	}
+
+	for uuidValue, coreToPod := range coreWise {
+		enriched := make(coreToPodInfo, len(coreToPod))
+		for coreIdx, podInformation := range coreToPod {
+			enriched[coreIdx] = p.enrich(podInformation)
+		}
+		coreWise[uuidValue] = enriched
+	}
+}
@@ -0,0 +1,254 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/furiosa-ai/furiosa-smi-go/pkg/smi"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	podResourcesV1Alpha1API "k8s.io/kubelet/pkg/apis/podresources/v1alpha1"
+)
+
+const fakePodUID = "1b2c3d4e-5f60-7a8b-9c0d-1e2f3a4b5c6d"
+
+// startFakeAPIServer serves the given pods on the pods API filtered by the node name field selector, and the given
+// objects by path. The watch requests are kept open without events. It returns a client, and a function returning the
+// number of requests of a path.
+func startFakeAPIServer(t *testing.T, objects map[string]interface{}, pods ...corev1.Pod) (kubernetes.Interface, func(path string) int) {
+	var lock sync.Mutex
+	requests := make(map[string]int)
+
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		requests[r.URL.Path]++
+		lock.Unlock()
+
+		if object, found := objects[r.URL.Path]; found {
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(object)
+			return
+		}
+
+		if r.URL.Path != "/api/v1/pods" {
+			http.NotFound(w, r)
+			return
+		}
+
+		if r.URL.Query().Get("watch") == "true" {
+			select {
+			case <-r.Context().Done():
+			case <-done:
+			}
+			return
+		}
+
+		list := corev1.PodList{
+			TypeMeta: metav1.TypeMeta{Kind: "PodList", APIVersion: "v1"},
+			ListMeta: metav1.ListMeta{ResourceVersion: "1"},
+		}
+		for _, pod := range pods {
+			if r.URL.Query().Get("fieldSelector") == "spec.nodeName="+pod.Spec.NodeName {
+				list.Items = append(list.Items, pod)
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(list)
+	}))
+	t.Cleanup(func() {
+		close(done)
+		server.Close()
+	})
+
+	client, err := kubernetes.NewForConfig(&rest.Config{Host: server.URL})
+	assert.NoError(t, err)
+
+	return client, func(path string) int {
+		lock.Lock()
+		defer lock.Unlock()
+
+		return requests[path]
+	}
+}
+
+func startFakePodMetadataInformer(t *testing.T, options PodMetadataOptions, pods ...corev1.Pod) *podMetadataInformer {
+	client, _ := startFakeAPIServer(t, nil, pods...)
+	informer, err := newPodMetadataInformer(client, options)
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	assert.NoError(t, informer.start(ctx))
+	assert.True(t, informer.informer.HasSynced())
+
+	return informer
+}
+
+func newFakePod(name, nodeName string) corev1.Pod {
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   "default",
+			UID:         fakePodUID,
+			Labels:      map[string]string{"team": "npu", "app.kubernetes.io/name": "llm-serve"},
+			Annotations: map[string]string{"example.com/cost-center": "1234"},
+		},
+		Spec: corev1.PodSpec{NodeName: nodeName},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "main", ContainerID: "containerd://" + fakeContainerID},
+			},
+		},
+	}
+}
+
+func TestMetadataLabelNames(t *testing.T) {
+	assert.Equal(t, map[string]string{
+		"team":                   "label_team",
+		"app.kubernetes.io/name": "label_app_kubernetes_io_name",
+	}, metadataLabelNames("label_", []string{"team", "app.kubernetes.io/name", "app_kubernetes_io/name", ""}))
+}
+
+func TestPodMetadataInformer_Enrich(t *testing.T) {
+	options := PodMetadataOptions{
+		Enabled:              true,
+		NodeName:             "node-a",
+		LabelsAllowlist:      []string{"team", "app.kubernetes.io/name"},
+		AnnotationsAllowlist: []string{"example.com/cost-center"},
+	}
+
+	// the pod on the other node is filtered by the field selector
+	informer := startFakePodMetadataInformer(t, options, newFakePod("pod-a", "node-a"), newFakePod("pod-b", "node-b"))
+	assert.Len(t, informer.informer.GetStore().List(), 1)
+	assert.Equal(t, []string{"label_team", "label_app_kubernetes_io_name", "annotation_example_com_cost_center"}, informer.labelNames(options))
+
+	expected := podInfo{
+		Name:          "pod-a",
+		Namespace:     "default",
+		ContainerName: "main",
+		UID:           fakePodUID,
+		ContainerID:   fakeContainerID,
+		Metadata: map[string]string{
+			"label_team":                         "npu",
+			"label_app_kubernetes_io_name":       "llm-serve",
+			"annotation_example_com_cost_center": "1234",
+		},
+	}
+
+	// the pod attributed by the kubelet is found by name, and the pod attributed by the cgroup is found by uid
+	kubeletPod := informer.enrich(podInfo{Name: "pod-a", Namespace: "default", ContainerName: "main", ContainerID: fakeContainerID})
+	assert.Equal(t, expected, kubeletPod)
+
+	cgroupPod := informer.enrich(podInfo{UID: fakePodUID, ContainerID: fakeContainerID})
+	assert.Equal(t, expected, cgroupPod)
+
+	unknownPod := informer.enrich(podInfo{Name: "pod-c", Namespace: "default"})
+	assert.Equal(t, podInfo{Name: "pod-c", Namespace: "default"}, unknownPod)
+}
+
+func TestKubeResourcesMapper_PodMetadataLabels(t *testing.T) {
+	options := PodMetadataOptions{
+		Enabled:         true,
+		NodeName:        "node-a",
+		LabelsAllowlist: []string{"team"},
+	}
+
+	informer := startFakePodMetadataInformer(t, options, newFakePod("pod-a", "node-a"))
+
+	deviceUUID := "A76AAD68-6855-40B1-9E86-D080852D1C80"
+	cores := newMockDeviceCores(t, smi.ArchRngd)
+	deviceWise := deviceWiseCache{
+		deviceUUID: {{Name: "pod-a", Namespace: "default", ContainerName: "main", AllocatedPE: cores[deviceUUID], CoreLabel: "0-7"}},
+	}
+	coreWise := coreWiseCache{}
+	informer.enrichCache(deviceWise, coreWise)
+
+	mapper := &kubeResourcesMapper{
+		enabled:         true,
+		deviceCores:     cores,
+		podMetadata:     informer,
+		metricLabels:    informer.labelNames(options),
+		deviceWiseCache: deviceWise,
+		coreWiseCache:   coreWise,
+	}
+
+	metric, err := NewMetricFactory("", "", false).NewDeviceWiseMetric(smi.GetStaticMockDevice(smi.ArchRngd, 0))
+	assert.NoError(t, err)
+
+	opts := prometheus.GaugeOpts{Name: "furiosa_npu_test", Help: "test"}
+	labelNames := defaultMetricLabels(mapper.MetricLabels()...)
+	gaugeVec := prometheus.NewGaugeVec(opts, labelNames)
+	for _, transformed := range mapper.TransformDeviceMetrics(MetricContainer{metric}, false) {
+		gaugeVec.With(metricLabels(labelNames, transformed, nil)).Set(1)
+	}
+
+	expected := `
+# HELP furiosa_npu_test test
+# TYPE furiosa_npu_test gauge
+furiosa_npu_test{arch="rngd",container="main",core="0-7",device="npu0",firmware_version="1.6.0+c1bebfd",label_team="npu",namespace="default",pci_bus_id="0000:27:00.0",pert_version="0.0.0+",pod="pod-a",pod_uid="1b2c3d4e-5f60-7a8b-9c0d-1e2f3a4b5c6d",uuid="A76AAD68-6855-40B1-9E86-D080852D1C80"} 1
+`
+	err = testutil.CollectAndCompare(NewLabelFilterCollector(gaugeVec, prometheus.Opts(opts), prometheus.GaugeValue), strings.NewReader(expected))
+	assert.NoError(t, err)
+}
+
+func TestKubeResourcesMapper_KubeletPodUID(t *testing.T) {
+	socket, _ := serveFakeKubelet(t, func(s *grpc.Server) {
+		podResourcesV1Alpha1API.RegisterPodResourcesListerServer(s, &fakeV1Alpha1PodResourcesServer{
+			resp: &podResourcesV1Alpha1API.ListPodResourcesResponse{
+				PodResources: []*podResourcesV1Alpha1API.PodResources{
+					{
+						Name:      "pod-a",
+						Namespace: "default",
+						Containers: []*podResourcesV1Alpha1API.ContainerResources{
+							{
+								Name: "main",
+								Devices: []*podResourcesV1Alpha1API.ContainerDevices{
+									{ResourceName: "furiosa.ai/rngd", DeviceIds: []string{"A76AAD68-6855-40B1-9E86-D080852D1C80"}},
+								},
+							},
+						},
+					},
+				},
+			},
+		})
+	})
+
+	client, err := newPodResourcesClient(socket)
+	assert.NoError(t, err)
+	t.Cleanup(client.close)
+
+	mapper := &kubeResourcesMapper{
+		enabled:         true,
+		logger:          zerolog.Nop(),
+		client:          client,
+		now:             time.Now,
+		deviceCores:     newMockDeviceCores(t, smi.ArchRngd),
+		deviceWiseCache: make(deviceWiseCache),
+	}
+
+	// the kubelet does not report the pod uid
+	mapper.syncPodInfoCache()
+	assert.Empty(t, mapper.allocatedPods("A76AAD68-6855-40B1-9E86-D080852D1C80")[0].UID)
+
+	// the pod uid is resolved by namespace and name with the pod informer
+	mapper.podMetadata = startFakePodMetadataInformer(t, PodMetadataOptions{Enabled: true, NodeName: "node-a"}, newFakePod("pod-a", "node-a"))
+
+	mapper.syncPodInfoCache()
+	assert.Equal(t, fakePodUID, mapper.allocatedPods("A76AAD68-6855-40B1-9E86-D080852D1C80")[0].UID)
+}
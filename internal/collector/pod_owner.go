@@ -0,0 +1,179 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// ownerCacheTTL is how long a resolved owner is cached, so that the owners of deleted workloads are eventually
+	// dropped from the cache.
+	ownerCacheTTL = 10 * time.Minute
+	// maxOwnerDepth bounds the owner chain, e.g. Pod -> StatefulSet -> Pod -> StatefulSet -> LeaderWorkerSet for the
+	// workers of a LeaderWorkerSet.
+	maxOwnerDepth = 5
+	// ownerQueueSize bounds the controllers waiting to be resolved.
+	ownerQueueSize = 256
+)
+
+type ownerKey struct {
+	namespace string
+	kind      string
+	name      string
+}
+
+type ownerEntry struct {
+	kind       string
+	name       string
+	resolvedAt time.Time
+	usedAt     time.Time
+}
+
+// podOwnerResolver resolves the top level workload owning a pod through the controller references, such as the
+// Deployment of a ReplicaSet, the CronJob of a Job, or the LeaderWorkerSet of a StatefulSet. The owners are resolved
+// by a background worker, so that the API server requests do not block the pod information syncs.
+type podOwnerResolver struct {
+	client kubernetes.Interface
+	now    func() time.Time
+	queue  chan ownerKey
+
+	sync.Mutex
+	// cache maps the direct controller of pods to the resolved owner.
+	cache map[ownerKey]ownerEntry
+	// pending holds the controllers queued to be resolved.
+	pending map[ownerKey]bool
+}
+
+func newPodOwnerResolver(client kubernetes.Interface, now func() time.Time) *podOwnerResolver {
+	return &podOwnerResolver{
+		client:  client,
+		now:     now,
+		queue:   make(chan ownerKey, ownerQueueSize),
+		cache:   make(map[ownerKey]ownerEntry),
+		pending: make(map[ownerKey]bool),
+	}
+}
+
+// start runs the worker resolving the queued controllers until the context is done.
+func (r *podOwnerResolver) start(ctx context.Context) {
+	go func() {
+		for {
+			select {
+			case key := <-r.queue:
+				kind, name := r.resolveOwner(key)
+
+				r.Lock()
+				entry := r.cache[key]
+				entry.kind, entry.name, entry.resolvedAt = kind, name, r.now()
+				if entry.usedAt.IsZero() {
+					entry.usedAt = entry.resolvedAt
+				}
+				r.cache[key] = entry
+				delete(r.pending, key)
+				r.Unlock()
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// resolve returns the kind and name of the top level owner of the pod, or empty strings if the pod has no controller
+// or its owner is not resolved yet. An owner older than ownerCacheTTL is still returned while it is resolved again.
+func (r *podOwnerResolver) resolve(pod *corev1.Pod) (string, string) {
+	controller := metav1.GetControllerOf(pod)
+	if controller == nil {
+		return "", ""
+	}
+
+	key := ownerKey{namespace: pod.Namespace, kind: controller.Kind, name: controller.Name}
+
+	r.Lock()
+	defer r.Unlock()
+
+	now := r.now()
+	for cached, entry := range r.cache {
+		if cached != key && now.Sub(entry.usedAt) > ownerCacheTTL {
+			delete(r.cache, cached)
+		}
+	}
+
+	entry, found := r.cache[key]
+	if !found || now.Sub(entry.resolvedAt) > ownerCacheTTL {
+		r.enqueue(key)
+	}
+
+	if !found {
+		return "", ""
+	}
+
+	entry.usedAt = now
+	r.cache[key] = entry
+
+	return entry.kind, entry.name
+}
+
+// enqueue queues the controller to be resolved unless it is already queued. If the queue is full, the controller is
+// queued again by the next resolve.
+func (r *podOwnerResolver) enqueue(key ownerKey) {
+	if r.pending[key] {
+		return
+	}
+
+	select {
+	case r.queue <- key:
+		r.pending[key] = true
+	default:
+	}
+}
+
+// resolveOwner follows the controller references from the given controller of a pod. The chain stops at the last
+// owner that can be read, so a missing permission results in an intermediate owner.
+func (r *podOwnerResolver) resolveOwner(key ownerKey) (string, string) {
+	kind, name := key.kind, key.name
+	for depth := 0; depth < maxOwnerDepth; depth++ {
+		next, err := r.controllerOf(key.namespace, kind, name)
+		if err != nil || next == nil {
+			break
+		}
+
+		kind, name = next.Kind, next.Name
+	}
+
+	return kind, name
+}
+
+// controllerOf returns the controller of the given object, or nil if the object is a top level owner such as a
+// Deployment, a CronJob or a LeaderWorkerSet.
+func (r *podOwnerResolver) controllerOf(namespace, kind, name string) (*metav1.OwnerReference, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var object metav1.Object
+	var err error
+	switch kind {
+	case "ReplicaSet":
+		object, err = r.client.AppsV1().ReplicaSets(namespace).Get(ctx, name, metav1.GetOptions{})
+	case "StatefulSet":
+		object, err = r.client.AppsV1().StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+	case "Job":
+		object, err = r.client.BatchV1().Jobs(namespace).Get(ctx, name, metav1.GetOptions{})
+	case "Pod":
+		// the worker StatefulSets of a LeaderWorkerSet are owned by the leader pod
+		object, err = r.client.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+	default:
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s '%s/%s'; err: %w", kind, namespace, name, err)
+	}
+
+	return metav1.GetControllerOf(object), nil
+}
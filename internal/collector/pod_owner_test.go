@@ -0,0 +1,171 @@
+package collector
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func controllerRef(kind, name string) []metav1.OwnerReference {
+	controller := true
+	return []metav1.OwnerReference{{Kind: kind, Name: name, Controller: &controller}}
+}
+
+func newOwnedPod(name string, owners []metav1.OwnerReference) *corev1.Pod {
+	return &corev1.Pod{
+		TypeMeta:   metav1.TypeMeta{Kind: "Pod", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", OwnerReferences: owners},
+	}
+}
+
+func TestPodOwnerResolver_Resolve(t *testing.T) {
+	objects := map[string]interface{}{
+		"/apis/apps/v1/namespaces/default/replicasets/llm-serve-7f9c8d": &appsv1.ReplicaSet{
+			TypeMeta:   metav1.TypeMeta{Kind: "ReplicaSet", APIVersion: "apps/v1"},
+			ObjectMeta: metav1.ObjectMeta{Name: "llm-serve-7f9c8d", Namespace: "default", OwnerReferences: controllerRef("Deployment", "llm-serve")},
+		},
+		"/apis/batch/v1/namespaces/default/jobs/backup-28000000": &batchv1.Job{
+			TypeMeta:   metav1.TypeMeta{Kind: "Job", APIVersion: "batch/v1"},
+			ObjectMeta: metav1.ObjectMeta{Name: "backup-28000000", Namespace: "default", OwnerReferences: controllerRef("CronJob", "backup")},
+		},
+		// the worker StatefulSet of a LeaderWorkerSet is owned by the leader pod, which is owned by the leader StatefulSet
+		"/apis/apps/v1/namespaces/default/statefulsets/lws-0": &appsv1.StatefulSet{
+			TypeMeta:   metav1.TypeMeta{Kind: "StatefulSet", APIVersion: "apps/v1"},
+			ObjectMeta: metav1.ObjectMeta{Name: "lws-0", Namespace: "default", OwnerReferences: controllerRef("Pod", "lws-0")},
+		},
+		"/api/v1/namespaces/default/pods/lws-0": newOwnedPod("lws-0", controllerRef("StatefulSet", "lws")),
+		"/apis/apps/v1/namespaces/default/statefulsets/lws": &appsv1.StatefulSet{
+			TypeMeta:   metav1.TypeMeta{Kind: "StatefulSet", APIVersion: "apps/v1"},
+			ObjectMeta: metav1.ObjectMeta{Name: "lws", Namespace: "default", OwnerReferences: controllerRef("LeaderWorkerSet", "lws")},
+		},
+		"/apis/apps/v1/namespaces/default/statefulsets/db": &appsv1.StatefulSet{
+			TypeMeta:   metav1.TypeMeta{Kind: "StatefulSet", APIVersion: "apps/v1"},
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"},
+		},
+	}
+
+	client, _ := startFakeAPIServer(t, objects)
+	now := time.Unix(1700000000, 0)
+	resolver := newPodOwnerResolver(client, func() time.Time { return now })
+
+	tests := []struct {
+		description  string
+		pod          *corev1.Pod
+		expectedKind string
+		expectedName string
+	}{
+		{
+			description:  "deployment",
+			pod:          newOwnedPod("llm-serve-7f9c8d-abcde", controllerRef("ReplicaSet", "llm-serve-7f9c8d")),
+			expectedKind: "Deployment",
+			expectedName: "llm-serve",
+		},
+		{
+			description:  "cronjob",
+			pod:          newOwnedPod("backup-28000000-xyz12", controllerRef("Job", "backup-28000000")),
+			expectedKind: "CronJob",
+			expectedName: "backup",
+		},
+		{
+			description:  "leaderworkerset worker",
+			pod:          newOwnedPod("lws-0-1", controllerRef("StatefulSet", "lws-0")),
+			expectedKind: "LeaderWorkerSet",
+			expectedName: "lws",
+		},
+		{
+			description:  "statefulset",
+			pod:          newOwnedPod("db-0", controllerRef("StatefulSet", "db")),
+			expectedKind: "StatefulSet",
+			expectedName: "db",
+		},
+		{
+			description:  "replicaset that cannot be read",
+			pod:          newOwnedPod("orphan-5d4c3b-fghij", controllerRef("ReplicaSet", "orphan-5d4c3b")),
+			expectedKind: "ReplicaSet",
+			expectedName: "orphan-5d4c3b",
+		},
+		{
+			description:  "daemonset",
+			pod:          newOwnedPod("agent-klmno", controllerRef("DaemonSet", "agent")),
+			expectedKind: "DaemonSet",
+			expectedName: "agent",
+		},
+		{
+			description: "standalone pod",
+			pod:         newOwnedPod("debug", nil),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			controller := metav1.GetControllerOf(tc.pod)
+			if controller == nil {
+				kind, name := resolver.resolve(tc.pod)
+				assert.Empty(t, kind)
+				assert.Empty(t, name)
+				return
+			}
+
+			kind, name := resolver.resolveOwner(ownerKey{namespace: tc.pod.Namespace, kind: controller.Kind, name: controller.Name})
+			assert.Equal(t, tc.expectedKind, kind)
+			assert.Equal(t, tc.expectedName, name)
+		})
+	}
+}
+
+func TestPodOwnerResolver_ResolveInBackground(t *testing.T) {
+	objects := map[string]interface{}{
+		"/apis/apps/v1/namespaces/default/replicasets/llm-serve-7f9c8d": &appsv1.ReplicaSet{
+			TypeMeta:   metav1.TypeMeta{Kind: "ReplicaSet", APIVersion: "apps/v1"},
+			ObjectMeta: metav1.ObjectMeta{Name: "llm-serve-7f9c8d", Namespace: "default", OwnerReferences: controllerRef("Deployment", "llm-serve")},
+		},
+	}
+
+	client, requests := startFakeAPIServer(t, objects)
+
+	var lock sync.Mutex
+	now := time.Unix(1700000000, 0)
+	resolver := newPodOwnerResolver(client, func() time.Time {
+		lock.Lock()
+		defer lock.Unlock()
+		return now
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	resolver.start(ctx)
+
+	pod := newOwnedPod("llm-serve-7f9c8d-abcde", controllerRef("ReplicaSet", "llm-serve-7f9c8d"))
+	replicaSetPath := "/apis/apps/v1/namespaces/default/replicasets/llm-serve-7f9c8d"
+
+	// the owner is unknown until the worker resolves it
+	kind, name := resolver.resolve(pod)
+	assert.Empty(t, kind)
+	assert.Empty(t, name)
+
+	assert.Eventually(t, func() bool {
+		kind, name := resolver.resolve(pod)
+		return kind == "Deployment" && name == "llm-serve"
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// the owner is cached per controller, until the ttl expires
+	resolver.resolve(newOwnedPod("llm-serve-7f9c8d-pqrst", controllerRef("ReplicaSet", "llm-serve-7f9c8d")))
+	assert.Equal(t, 1, requests(replicaSetPath))
+
+	// the expired owner is still returned while it is resolved again
+	lock.Lock()
+	now = now.Add(ownerCacheTTL + time.Second)
+	lock.Unlock()
+
+	kind, name = resolver.resolve(pod)
+	assert.Equal(t, "Deployment", kind)
+	assert.Equal(t, "llm-serve", name)
+	assert.Eventually(t, func() bool { return requests(replicaSetPath) == 2 }, 5*time.Second, 10*time.Millisecond)
+}
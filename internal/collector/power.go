@@ -2,28 +2,45 @@ package collector
 
 import (
 	"errors"
+	"strings"
+	"time"
+
 	"github.com/furiosa-ai/furiosa-smi-go/pkg/smi"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
 const (
-	rms = "rms"
+	rms           = "rms"
+	powerHeadroom = "powerHeadroom"
+
+	energyDelta      = "energyDelta"
+	energyTotal      = "energyTotal"
+	energyDeviceCore = "energyDeviceCore"
 )
 
 type powerCollector struct {
-	devices       []smi.Device
-	metricFactory MetricFactory
-	gaugeVec      *prometheus.GaugeVec
-	kubeResMapper KubeResourcesMapper
+	devices          []smi.Device
+	metricFactory    MetricFactory
+	gaugeVec         *prometheus.GaugeVec
+	energyCounterVec *prometheus.CounterVec
+	headroomGaugeVec *prometheus.GaugeVec
+	kubeResMapper    KubeResourcesMapper
+	limits           DeviceLimits
+	samples          *DeviceSamples
+
+	energyIntegrator *energyIntegrator
 }
 
 var _ Collector = (*powerCollector)(nil)
 
-func NewPowerCollector(devices []smi.Device, metricFactory MetricFactory, kubeResMapper KubeResourcesMapper) Collector {
+func NewPowerCollector(devices []smi.Device, metricFactory MetricFactory, kubeResMapper KubeResourcesMapper, limits DeviceLimits, samples *DeviceSamples) Collector {
 	return &powerCollector{
-		devices:       devices,
-		metricFactory: metricFactory,
-		kubeResMapper: kubeResMapper,
+		devices:          devices,
+		metricFactory:    metricFactory,
+		kubeResMapper:    kubeResMapper,
+		limits:           limits,
+		samples:          samples,
+		energyIntegrator: newEnergyIntegrator(time.Now),
 	}
 }
 
@@ -33,13 +50,39 @@ func (t *powerCollector) Register() {
 		Help: "The current power of NPU device",
 	}
 
-	t.gaugeVec = prometheus.NewGaugeVec(opts, append(defaultMetricLabels(), label))
+	t.gaugeVec = prometheus.NewGaugeVec(opts, append(defaultMetricLabels(t.kubeResMapper.MetricLabels()...), label))
 
 	prometheus.MustRegister(NewLabelFilterCollector(
 		t.gaugeVec,
 		prometheus.Opts(opts),
 		prometheus.GaugeValue,
 	))
+
+	energyOpts := prometheus.CounterOpts{
+		Name: "furiosa_npu_energy_joules_total",
+		Help: "The accumulated energy consumption of NPU device (J)",
+	}
+
+	t.energyCounterVec = prometheus.NewCounterVec(energyOpts, defaultMetricLabels(t.kubeResMapper.MetricLabels()...))
+
+	prometheus.MustRegister(NewLabelFilterCollector(
+		t.energyCounterVec,
+		prometheus.Opts(energyOpts),
+		prometheus.CounterValue,
+	))
+
+	headroomOpts := prometheus.GaugeOpts{
+		Name: "furiosa_npu_power_headroom_watts",
+		Help: "The difference between the power limit and the current power of NPU device (W)",
+	}
+
+	t.headroomGaugeVec = prometheus.NewGaugeVec(headroomOpts, defaultMetricLabels(t.kubeResMapper.MetricLabels()...))
+
+	prometheus.MustRegister(NewLabelFilterCollector(
+		t.headroomGaugeVec,
+		prometheus.Opts(headroomOpts),
+		prometheus.GaugeValue,
+	))
 }
 
 func (t *powerCollector) Collect() error {
@@ -55,11 +98,21 @@ func (t *powerCollector) Collect() error {
 
 		power, err := d.PowerConsumption()
 		if err != nil {
+			t.samples.forgetPower(metric[uuid].(string))
 			errs = append(errs, err)
 			continue
 		}
 
+		delta, total := t.energyIntegrator.integrate(metric[uuid].(string), power)
+		t.samples.setPower(metric[uuid].(string), powerSample{watts: power, joules: total})
+
 		metric[rms] = power
+		metric[energyDelta] = delta
+		metric[energyTotal] = total
+		metric[energyDeviceCore] = metric[core]
+		if archLimits, found := t.limits.forArch(metric[arch].(string)); found {
+			metric[powerHeadroom] = archLimits.PowerLimit - power
+		}
 		metricContainer = append(metricContainer, metric)
 	}
 
@@ -75,28 +128,65 @@ func (t *powerCollector) Collect() error {
 }
 
 func (t *powerCollector) postProcess(metrics MetricContainer) error {
+	labelNames := defaultMetricLabels(t.kubeResMapper.MetricLabels()...)
 	transformed := t.kubeResMapper.TransformDeviceMetrics(metrics, false)
 	t.gaugeVec.Reset()
+	t.energyCounterVec.Reset()
+	t.headroomGaugeVec.Reset()
+
+	// the energy of attributed series is accumulated from the share of each interval, so that a pod is only charged
+	// for the energy consumed while the device is allocated to it.
+	shareDeltas := make(map[string]float64)
+	for _, metric := range transformed {
+		if value, ok := metric[energyDelta]; ok && isAttributed(metric) {
+			share := coreShare(metric[core].(string), metric[energyDeviceCore].(string))
+			shareDeltas[energySeriesKey(metric)] = value.(float64) * share
+		}
+	}
+	shares := t.energyIntegrator.accumulateShares(shareDeltas)
 
 	for _, metric := range transformed {
 		if value, ok := metric["rms"]; ok {
-			t.gaugeVec.With(prometheus.Labels{
-				arch:                metric[arch].(string),
-				core:                metric[core].(string),
-				device:              metric[device].(string),
-				label:               rms,
-				uuid:                metric[uuid].(string),
-				bdf:                 metric[bdf].(string),
-				firmwareVersion:     metric[firmwareVersion].(string),
-				pertVersion:         metric[pertVersion].(string),
-				driverVersion:       metric[driverVersion].(string),
-				hostname:            metric[hostname].(string),
-				kubernetesNamespace: metric[kubernetesNamespace].(string),
-				kubernetesPod:       metric[kubernetesPod].(string),
-				kubernetesContainer: metric[kubernetesContainer].(string),
-			}).Set(value.(float64))
+			t.gaugeVec.With(metricLabels(labelNames, metric, prometheus.Labels{label: rms})).Set(value.(float64))
+		}
+
+		if value, ok := metric[powerHeadroom]; ok {
+			t.headroomGaugeVec.With(metricLabels(labelNames, metric, nil)).Set(value.(float64))
+		}
+
+		if value, ok := metric[energyTotal]; ok {
+			energy := value.(float64)
+			if isAttributed(metric) {
+				energy = shares[energySeriesKey(metric)]
+			}
+
+			t.energyCounterVec.With(metricLabels(labelNames, metric, nil)).Add(energy)
 		}
 	}
 
 	return nil
 }
+
+func energySeriesKey(metric Metric) string {
+	return strings.Join([]string{
+		metric[uuid].(string),
+		metric[core].(string),
+		metric[kubernetesNamespace].(string),
+		metric[kubernetesPod].(string),
+		metric[kubernetesContainer].(string),
+		metric[kubernetesPodUID].(string),
+		metric[containerID].(string),
+	}, "/")
+}
+
+// isAttributed reports whether the metric is attributed to a container. The checkpoint and cgroup fallbacks may only
+// know the pod uid or the container id.
+func isAttributed(metric Metric) bool {
+	for _, l := range []string{kubernetesPod, kubernetesPodUID, containerID} {
+		if value, _ := metric[l].(string); value != "" {
+			return true
+		}
+	}
+
+	return false
+}
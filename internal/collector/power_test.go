@@ -3,6 +3,7 @@ package collector
 import (
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/testutil"
@@ -11,14 +12,16 @@ import (
 
 func newFakePowerCollector() Collector {
 	return &powerCollector{
-		devices:       nil,
-		metricFactory: nil,
-		kubeResMapper: NewFakeKubeResourcesMapper(),
+		devices:          nil,
+		metricFactory:    nil,
+		kubeResMapper:    NewFakeKubeResourcesMapper(),
+		energyIntegrator: newEnergyIntegrator(time.Now),
 	}
 }
 
 func TestPowerCollector_PostProcessing(t *testing.T) {
 	collector := newFakePowerCollector()
+	useTestRegistry(t)
 	collector.Register()
 
 	tc := MetricContainer{}
@@ -30,6 +33,7 @@ func TestPowerCollector_PostProcessing(t *testing.T) {
 	metric[bdf] = "bdf"
 	metric[label] = rms
 	metric[rms] = float64(4795000)
+	metric[powerHeadroom] = float64(80)
 
 	tc = append(tc, metric)
 
@@ -40,9 +44,12 @@ func TestPowerCollector_PostProcessing(t *testing.T) {
 # HELP furiosa_npu_hw_power The current power of NPU device
 # TYPE furiosa_npu_hw_power gauge
 furiosa_npu_hw_power{arch="rngd",core="0-7",device="npu0",label="rms",pci_bus_id="bdf",uuid="uuid"} 4795000
+# HELP furiosa_npu_power_headroom_watts The difference between the power limit and the current power of NPU device (W)
+# TYPE furiosa_npu_power_headroom_watts gauge
+furiosa_npu_power_headroom_watts{arch="rngd",core="0-7",device="npu0",pci_bus_id="bdf",uuid="uuid"} 80
 `
 
-	err = testutil.GatherAndCompare(prometheus.DefaultGatherer, strings.NewReader(expected), "furiosa_npu_hw_power")
+	err = testutil.GatherAndCompare(prometheus.DefaultGatherer, strings.NewReader(expected), "furiosa_npu_hw_power", "furiosa_npu_power_headroom_watts")
 	assert.NoError(t, err)
 }
 
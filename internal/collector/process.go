@@ -0,0 +1,397 @@
+package collector
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/furiosa-ai/furiosa-smi-go/pkg/smi"
+	"github.com/rs/zerolog"
+)
+
+type processInfo struct {
+	PID       int
+	Comm      string
+	User      string
+	Arch      string
+	Device    string
+	UUID      string
+	Cores     []int
+	CoreLabel string
+}
+
+// deviceFileOwner describes the device and the cores a device file gives access to.
+type deviceFileOwner struct {
+	arch   string
+	device string
+	uuid   string
+	cores  []uint32
+}
+
+// processDeviceWiseCache maps uuid to the processes holding the device for device wise metrics
+type processDeviceWiseCache map[string][]processInfo
+
+// processCoreWiseCache maps uuid to core to the processes holding the core for core wise metrics
+type processCoreWiseCache map[string]map[int][]processInfo
+
+// ProcessResourcesMapper is a KubeResourcesMapper alternative for bare-metal hosts, which attributes the metrics to
+// the processes holding the NPU device files open.
+type ProcessResourcesMapper interface {
+	KubeResourcesMapper
+	processes() []processInfo
+}
+
+// deviceFileScanner finds the processes holding the NPU device files open by scanning `<procRoot>/*/fd`.
+type deviceFileScanner struct {
+	procRoot    string
+	deviceFiles map[string]deviceFileOwner
+	coreNums    map[string]int
+}
+
+type processResourcesMapper struct {
+	*deviceFileScanner
+	logger zerolog.Logger
+	sync.RWMutex
+	processDeviceWiseCache
+	processCoreWiseCache
+}
+
+var _ ProcessResourcesMapper = (*processResourcesMapper)(nil)
+
+// NewProcessResourcesMapper creates a mapper scanning `<procRoot>/*/fd` for handles to the device files of the given
+// devices whenever the returned channel is triggered.
+func NewProcessResourcesMapper(ctx context.Context, logger zerolog.Logger, procRoot string, devices []smi.Device) (ProcessResourcesMapper, chan<- struct{}, error) {
+	mapper, err := newProcessResourcesMapper(procRoot, devices)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mapper.logger = logger
+
+	syncChan := make(chan struct{}, 1)
+
+	go func() {
+		for {
+			select {
+			case <-syncChan:
+				mapper.syncProcessInfoCache()
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return mapper, syncChan, nil
+}
+
+func newProcessResourcesMapper(procRoot string, devices []smi.Device) (*processResourcesMapper, error) {
+	scanner, err := newDeviceFileScanner(procRoot, devices)
+	if err != nil {
+		return nil, err
+	}
+
+	return &processResourcesMapper{
+		deviceFileScanner:      scanner,
+		processDeviceWiseCache: make(processDeviceWiseCache),
+		processCoreWiseCache:   make(processCoreWiseCache),
+	}, nil
+}
+
+func newDeviceFileScanner(procRoot string, devices []smi.Device) (*deviceFileScanner, error) {
+	scanner := &deviceFileScanner{
+		procRoot:    procRoot,
+		deviceFiles: make(map[string]deviceFileOwner),
+		coreNums:    make(map[string]int),
+	}
+
+	for _, d := range devices {
+		info, err := getDeviceInfo(d)
+		if err != nil {
+			return nil, err
+		}
+
+		scanner.coreNums[info.uuid] = len(info.cores)
+		for _, file := range info.deviceFiles {
+			scanner.deviceFiles[file.path] = deviceFileOwner{
+				arch:   info.arch,
+				device: info.device,
+				uuid:   info.uuid,
+				cores:  file.cores,
+			}
+		}
+	}
+
+	return scanner, nil
+}
+
+// scan calls fn for each process holding at least one device file, with the held devices keyed by uuid. The
+// processes that exit or are inaccessible while scanning are skipped.
+func (s *deviceFileScanner) scan(fn func(pid int, held map[string]deviceFileOwner)) error {
+	entries, err := os.ReadDir(s.procRoot)
+	if err != nil {
+		return fmt.Errorf("failed to read proc root '%s'; err: %w", s.procRoot, err)
+	}
+
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+
+		held := s.scanDeviceFiles(pid)
+		if len(held) == 0 {
+			continue
+		}
+
+		fn(pid, held)
+	}
+
+	return nil
+}
+
+func (p *processResourcesMapper) syncProcessInfoCache() {
+	deviceWise, coreWise, err := p.buildProcessCache()
+	if err != nil {
+		p.logger.Warn().Err(err).Msg("failed to get process information cache")
+		return
+	}
+
+	p.Lock()
+	defer p.Unlock()
+
+	p.processDeviceWiseCache = deviceWise
+	p.processCoreWiseCache = coreWise
+}
+
+func (p *processResourcesMapper) buildProcessCache() (processDeviceWiseCache, processCoreWiseCache, error) {
+	deviceWise := make(processDeviceWiseCache)
+	coreWise := make(processCoreWiseCache)
+
+	err := p.scan(func(pid int, heldCores map[string]deviceFileOwner) {
+		comm, processUser := p.readProcessIdentity(pid)
+
+		uuids := make([]string, 0, len(heldCores))
+		for uuidValue := range heldCores {
+			uuids = append(uuids, uuidValue)
+		}
+		slices.Sort(uuids)
+
+		for _, uuidValue := range uuids {
+			owner := heldCores[uuidValue]
+			cores := uniqueSortedCores(owner.cores)
+
+			process := processInfo{
+				PID:       pid,
+				Comm:      comm,
+				User:      processUser,
+				Arch:      owner.arch,
+				Device:    owner.device,
+				UUID:      uuidValue,
+				Cores:     make([]int, 0, len(cores)),
+				CoreLabel: formatCoreLabel(cores),
+			}
+			for _, c := range cores {
+				process.Cores = append(process.Cores, int(c))
+			}
+
+			// build device wise cache
+			deviceWise[uuidValue] = append(deviceWise[uuidValue], process)
+
+			// build core wise cache
+			if _, ok := coreWise[uuidValue]; !ok {
+				coreWise[uuidValue] = make(map[int][]processInfo)
+			}
+
+			for _, coreIdx := range process.Cores {
+				coreWise[uuidValue][coreIdx] = append(coreWise[uuidValue][coreIdx], process)
+			}
+		}
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return deviceWise, coreWise, nil
+}
+
+// uniqueSortedCores returns the given cores deduplicated and sorted.
+func uniqueSortedCores(cores []uint32) []uint32 {
+	unique := make([]uint32, 0, len(cores))
+	for _, c := range cores {
+		if !slices.Contains(unique, c) {
+			unique = append(unique, c)
+		}
+	}
+	slices.Sort(unique)
+
+	return unique
+}
+
+// scanDeviceFiles returns the device files held by the process grouped by device uuid, with the cores accumulated.
+func (s *deviceFileScanner) scanDeviceFiles(pid int) map[string]deviceFileOwner {
+	fdDir := filepath.Join(s.procRoot, strconv.Itoa(pid), "fd")
+	fds, err := os.ReadDir(fdDir)
+	if err != nil {
+		return nil
+	}
+
+	held := make(map[string]deviceFileOwner)
+	for _, fd := range fds {
+		target, err := os.Readlink(filepath.Join(fdDir, fd.Name()))
+		if err != nil {
+			continue
+		}
+
+		owner, found := s.deviceFiles[target]
+		if !found {
+			continue
+		}
+
+		accumulated, ok := held[owner.uuid]
+		if !ok {
+			accumulated = deviceFileOwner{arch: owner.arch, device: owner.device, uuid: owner.uuid}
+		}
+		accumulated.cores = append(accumulated.cores, owner.cores...)
+		held[owner.uuid] = accumulated
+	}
+
+	return held
+}
+
+// readProcessIdentity returns the command name and the user name of the process. The user falls back to the numeric
+// uid when it cannot be resolved on this host.
+func (s *deviceFileScanner) readProcessIdentity(pid int) (string, string) {
+	processDir := filepath.Join(s.procRoot, strconv.Itoa(pid))
+
+	comm := ""
+	if raw, err := os.ReadFile(filepath.Join(processDir, "comm")); err == nil {
+		comm = strings.TrimSpace(string(raw))
+	}
+
+	uid := readProcessUID(filepath.Join(processDir, "status"))
+	if uid == "" {
+		return comm, ""
+	}
+
+	if u, err := user.LookupId(uid); err == nil {
+		return comm, u.Username
+	}
+
+	return comm, uid
+}
+
+// readProcessUID returns the real uid from the `Uid:` line of the given status file.
+func readProcessUID(statusPath string) string {
+	file, err := os.Open(statusPath)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "Uid:" {
+			return fields[1]
+		}
+	}
+
+	return ""
+}
+
+func (p *processResourcesMapper) processes() []processInfo {
+	p.RLock()
+	defer p.RUnlock()
+
+	uuids := make([]string, 0, len(p.processDeviceWiseCache))
+	for uuidValue := range p.processDeviceWiseCache {
+		uuids = append(uuids, uuidValue)
+	}
+	slices.Sort(uuids)
+
+	result := make([]processInfo, 0)
+	for _, uuidValue := range uuids {
+		result = append(result, p.processDeviceWiseCache[uuidValue]...)
+	}
+
+	return result
+}
+
+func (p *processResourcesMapper) TransformDeviceMetrics(metrics MetricContainer, coreWiseMetric bool) MetricContainer {
+	transformed := make(MetricContainer, 0)
+
+	p.RLock()
+	defer p.RUnlock()
+
+	for _, metric := range metrics {
+		uuidValue, uuidFound := metric[uuid].(string)
+		if !uuidFound {
+			transformed = append(transformed, metric)
+			continue
+		}
+
+		var processSlice []processInfo
+		if coreWiseMetric {
+			// handle core wise metrics like utilization and performance counter
+			coreValue, coreFound := metric[core].(string)
+			if !coreFound {
+				transformed = append(transformed, metric)
+				continue
+			}
+
+			coreIdx, err := strconv.Atoi(coreValue)
+			if err != nil {
+				transformed = append(transformed, metric)
+				continue
+			}
+
+			processSlice = p.processCoreWiseCache[uuidValue][coreIdx]
+		} else {
+			// handle device wise metrics
+			processSlice = p.processDeviceWiseCache[uuidValue]
+		}
+
+		if len(processSlice) == 0 {
+			transformed = append(transformed, metric)
+			continue
+		}
+
+		if len(processSlice) == 1 && (coreWiseMetric || len(processSlice[0].Cores) == p.coreNums[uuidValue]) {
+			// exclusive case, a single process holds the whole core or device
+			copied := withProcessLabels(metric, processSlice[0], !coreWiseMetric)
+			transformed = append(transformed, copied)
+		} else {
+			// shared case, preserve origin metric and duplicate the metric for each process
+			transformed = append(transformed, metric)
+			for _, process := range processSlice {
+				transformed = append(transformed, withProcessLabels(metric, process, !coreWiseMetric))
+			}
+		}
+	}
+
+	return transformed
+}
+
+// MetricLabels returns the process labels, which are only added to the metrics in the process labeling mode.
+func (p *processResourcesMapper) MetricLabels() []string {
+	return []string{processID, processComm, processUser}
+}
+
+func withProcessLabels(metric Metric, process processInfo, overrideCore bool) Metric {
+	copied := deepCopyMetric(metric)
+	copied[processID] = strconv.Itoa(process.PID)
+	copied[processComm] = process.Comm
+	copied[processUser] = process.User
+	if overrideCore {
+		copied[core] = process.CoreLabel
+	}
+
+	return copied
+}
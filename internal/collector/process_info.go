@@ -0,0 +1,75 @@
+package collector
+
+import (
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// processInfoCollector exports a `furiosa_npu_process_info` series per process and device pair found by the
+// process resources mapper.
+type processInfoCollector struct {
+	mapper   ProcessResourcesMapper
+	gaugeVec *prometheus.GaugeVec
+}
+
+var _ Collector = (*processInfoCollector)(nil)
+
+func NewProcessInfoCollector(mapper ProcessResourcesMapper) Collector {
+	return &processInfoCollector{
+		mapper: mapper,
+	}
+}
+
+func (t *processInfoCollector) Register() {
+	opts := prometheus.GaugeOpts{
+		Name: "furiosa_npu_process_info",
+		Help: "The process holding the NPU device files open. The value is always 1",
+	}
+
+	t.gaugeVec = prometheus.NewGaugeVec(opts, []string{arch, device, uuid, core, processID, processComm, processUser})
+
+	prometheus.MustRegister(NewLabelFilterCollector(
+		t.gaugeVec,
+		prometheus.Opts(opts),
+		prometheus.GaugeValue,
+	))
+}
+
+func (t *processInfoCollector) Collect() error {
+	metricContainer := make(MetricContainer, 0)
+
+	for _, process := range t.mapper.processes() {
+		metric := Metric{
+			arch:        process.Arch,
+			device:      process.Device,
+			uuid:        process.UUID,
+			core:        process.CoreLabel,
+			processID:   strconv.Itoa(process.PID),
+			processComm: process.Comm,
+			processUser: process.User,
+		}
+
+		metricContainer = append(metricContainer, metric)
+	}
+
+	return t.postProcess(metricContainer)
+}
+
+func (t *processInfoCollector) postProcess(metrics MetricContainer) error {
+	t.gaugeVec.Reset()
+
+	for _, metric := range metrics {
+		t.gaugeVec.With(prometheus.Labels{
+			arch:        metric[arch].(string),
+			device:      metric[device].(string),
+			uuid:        metric[uuid].(string),
+			core:        metric[core].(string),
+			processID:   metric[processID].(string),
+			processComm: metric[processComm].(string),
+			processUser: metric[processUser].(string),
+		}).Set(1)
+	}
+
+	return nil
+}
@@ -0,0 +1,120 @@
+package collector
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/furiosa-ai/furiosa-smi-go/pkg/smi"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// writeFakeProcess creates a fake `<procRoot>/<pid>` entry whose fds link to the given paths.
+func writeFakeProcess(t *testing.T, procRoot string, pid int, comm string, uid string, paths ...string) {
+	dir := filepath.Join(procRoot, strconv.Itoa(pid))
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "fd"), 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "comm"), []byte(comm+"\n"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "status"), []byte("Name:\t"+comm+"\nUid:\t"+uid+"\t"+uid+"\t"+uid+"\t"+uid+"\n"), 0644))
+
+	for i, path := range paths {
+		assert.NoError(t, os.Symlink(path, filepath.Join(dir, "fd", strconv.Itoa(i))))
+	}
+}
+
+func newFakeProcessResourcesMapper(t *testing.T) *processResourcesMapper {
+	procRoot := t.TempDir()
+	writeFakeProcess(t, procRoot, 100, "python", "4000000000", "/dev/null", "/dev/rngd/npu0pe0-3", "/dev/rngd/npu0pe0")
+	writeFakeProcess(t, procRoot, 200, "serve", "4000000001", "/dev/rngd/npu0pe4-7", "/dev/rngd/npu1pe0-3", "/dev/rngd/npu1pe4-7")
+	writeFakeProcess(t, procRoot, 300, "bash", "4000000001", "/dev/null")
+	assert.NoError(t, os.MkdirAll(filepath.Join(procRoot, "self"), 0755))
+
+	mapper, err := newProcessResourcesMapper(procRoot, smi.GetStaticMockDevices(smi.ArchRngd)[:2])
+	assert.NoError(t, err)
+
+	mapper.syncProcessInfoCache()
+
+	return mapper
+}
+
+func TestProcessResourcesMapper_Processes(t *testing.T) {
+	mapper := newFakeProcessResourcesMapper(t)
+
+	processes := mapper.processes()
+	assert.Len(t, processes, 3)
+
+	npu0 := mapper.processDeviceWiseCache["A76AAD68-6855-40B1-9E86-D080852D1C80"]
+	assert.Len(t, npu0, 2)
+	assert.Equal(t, 100, npu0[0].PID)
+	assert.Equal(t, "python", npu0[0].Comm)
+	assert.Equal(t, "4000000000", npu0[0].User)
+	assert.Equal(t, []int{0, 1, 2, 3}, npu0[0].Cores)
+	assert.Equal(t, "0-3", npu0[0].CoreLabel)
+	assert.Equal(t, 200, npu0[1].PID)
+	assert.Equal(t, "4-7", npu0[1].CoreLabel)
+}
+
+func TestProcessResourcesMapper_TransformDeviceMetrics(t *testing.T) {
+	mapper := newFakeProcessResourcesMapper(t)
+
+	devices := smi.GetStaticMockDevices(smi.ArchRngd)[:2]
+	metricFactory := NewMetricFactory("", "", false)
+
+	deviceWiseMetrics := MetricContainer{}
+	for _, d := range devices {
+		metric, err := metricFactory.NewDeviceWiseMetric(d)
+		assert.NoError(t, err)
+		deviceWiseMetrics = append(deviceWiseMetrics, metric)
+	}
+
+	// npu0 is shared by two processes, npu1 is held by a single process exclusively
+	transformed := mapper.TransformDeviceMetrics(deviceWiseMetrics, false)
+	assert.Len(t, transformed, 4)
+
+	assert.NotContains(t, transformed[0], processID)
+	assert.Equal(t, "0-7", transformed[0][core])
+	assert.Equal(t, "100", transformed[1][processID])
+	assert.Equal(t, "0-3", transformed[1][core])
+	assert.Equal(t, "200", transformed[2][processID])
+	assert.Equal(t, "4-7", transformed[2][core])
+	assert.Equal(t, "npu1", transformed[3][device])
+	assert.Equal(t, "200", transformed[3][processID])
+	assert.Equal(t, "serve", transformed[3][processComm])
+	assert.Equal(t, "4000000001", transformed[3][processUser])
+	assert.Equal(t, "0-7", transformed[3][core])
+
+	// the process labels are only added to the metric labels in the process labeling mode
+	assert.NotContains(t, defaultMetricLabels(), processID)
+	assert.Equal(t, []string{processID, processComm, processUser}, mapper.MetricLabels())
+
+	coreWiseMetric := deepCopyMetric(deviceWiseMetrics[0])
+	coreWiseMetric[core] = "2"
+
+	transformed = mapper.TransformDeviceMetrics(MetricContainer{coreWiseMetric}, true)
+	assert.Len(t, transformed, 1)
+	assert.Equal(t, "100", transformed[0][processID])
+	assert.Equal(t, "2", transformed[0][core])
+}
+
+func TestProcessInfoCollector_Collect(t *testing.T) {
+	collector := NewProcessInfoCollector(newFakeProcessResourcesMapper(t))
+	useTestRegistry(t)
+	collector.Register()
+
+	err := collector.Collect()
+	assert.NoError(t, err)
+
+	expected := `
+# HELP furiosa_npu_process_info The process holding the NPU device files open. The value is always 1
+# TYPE furiosa_npu_process_info gauge
+furiosa_npu_process_info{arch="rngd",comm="python",core="0-3",device="npu0",pid="100",user="4000000000",uuid="A76AAD68-6855-40B1-9E86-D080852D1C80"} 1
+furiosa_npu_process_info{arch="rngd",comm="serve",core="4-7",device="npu0",pid="200",user="4000000001",uuid="A76AAD68-6855-40B1-9E86-D080852D1C80"} 1
+furiosa_npu_process_info{arch="rngd",comm="serve",core="0-7",device="npu1",pid="200",user="4000000001",uuid="A76AAD68-6855-40B1-9E86-D080852D1C81"} 1
+`
+	err = testutil.GatherAndCompare(prometheus.DefaultGatherer, strings.NewReader(expected), "furiosa_npu_process_info")
+	assert.NoError(t, err)
+}
@@ -2,29 +2,36 @@ package collector
 
 import (
 	"errors"
+
 	"github.com/furiosa-ai/furiosa-smi-go/pkg/smi"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
 type temperatureCollector struct {
-	devices       []smi.Device
-	metricFactory MetricFactory
-	gaugeVec      *prometheus.GaugeVec
-	kubeResMapper KubeResourcesMapper
+	devices                 []smi.Device
+	metricFactory           MetricFactory
+	gaugeVec                *prometheus.GaugeVec
+	thermalHeadroomGaugeVec *prometheus.GaugeVec
+	throttledGaugeVec       *prometheus.GaugeVec
+	kubeResMapper           KubeResourcesMapper
+	limits                  DeviceLimits
 }
 
 const (
-	ambient = "ambient"
-	peak    = "peak"
+	ambient         = "ambient"
+	peak            = "peak"
+	thermalHeadroom = "thermalHeadroom"
+	throttled       = "throttled"
 )
 
 var _ Collector = (*temperatureCollector)(nil)
 
-func NewTemperatureCollector(devices []smi.Device, metricFactory MetricFactory, kubeResMapper KubeResourcesMapper) Collector {
+func NewTemperatureCollector(devices []smi.Device, metricFactory MetricFactory, kubeResMapper KubeResourcesMapper, limits DeviceLimits) Collector {
 	return &temperatureCollector{
 		devices:       devices,
 		metricFactory: metricFactory,
 		kubeResMapper: kubeResMapper,
+		limits:        limits,
 	}
 }
 
@@ -34,13 +41,39 @@ func (t *temperatureCollector) Register() {
 		Help: "The current temperature of NPU device",
 	}
 
-	t.gaugeVec = prometheus.NewGaugeVec(opts, append(defaultMetricLabels(), label))
+	t.gaugeVec = prometheus.NewGaugeVec(opts, append(defaultMetricLabels(t.kubeResMapper.MetricLabels()...), label))
 
 	prometheus.MustRegister(NewLabelFilterCollector(
 		t.gaugeVec,
 		prometheus.Opts(opts),
 		prometheus.GaugeValue,
 	))
+
+	thermalHeadroomOpts := prometheus.GaugeOpts{
+		Name: "furiosa_npu_thermal_headroom_celsius",
+		Help: "The difference between the thermal limit and the current peak temperature of NPU device (Celsius)",
+	}
+
+	t.thermalHeadroomGaugeVec = prometheus.NewGaugeVec(thermalHeadroomOpts, defaultMetricLabels(t.kubeResMapper.MetricLabels()...))
+
+	prometheus.MustRegister(NewLabelFilterCollector(
+		t.thermalHeadroomGaugeVec,
+		prometheus.Opts(thermalHeadroomOpts),
+		prometheus.GaugeValue,
+	))
+
+	throttledOpts := prometheus.GaugeOpts{
+		Name: "furiosa_npu_throttled",
+		Help: "Whether NPU device is likely throttled, i.e. its peak temperature is close to the thermal limit while a core runs under the nominal frequency",
+	}
+
+	t.throttledGaugeVec = prometheus.NewGaugeVec(throttledOpts, defaultMetricLabels(t.kubeResMapper.MetricLabels()...))
+
+	prometheus.MustRegister(NewLabelFilterCollector(
+		t.throttledGaugeVec,
+		prometheus.Opts(throttledOpts),
+		prometheus.GaugeValue,
+	))
 }
 
 func (t *temperatureCollector) Collect() error {
@@ -62,6 +95,19 @@ func (t *temperatureCollector) Collect() error {
 
 		metric[ambient] = deviceTemperature.Ambient()
 		metric[peak] = deviceTemperature.SocPeak()
+
+		if archLimits, found := t.limits.forArch(metric[arch].(string)); found {
+			headroom := archLimits.ThermalLimit - deviceTemperature.SocPeak()
+			metric[thermalHeadroom] = headroom
+
+			coreFrequency, err := d.CoreFrequency()
+			if err != nil {
+				errs = append(errs, err)
+			} else {
+				metric[throttled] = isThrottled(headroom, coreFrequency, archLimits.NominalCoreFrequency)
+			}
+		}
+
 		metricContainer = append(metricContainer, metric)
 	}
 
@@ -77,46 +123,52 @@ func (t *temperatureCollector) Collect() error {
 }
 
 func (t *temperatureCollector) postProcess(metrics MetricContainer) error {
+	labelNames := defaultMetricLabels(t.kubeResMapper.MetricLabels()...)
 	transformed := t.kubeResMapper.TransformDeviceMetrics(metrics, false)
 	t.gaugeVec.Reset()
+	t.thermalHeadroomGaugeVec.Reset()
+	t.throttledGaugeVec.Reset()
 
 	for _, metric := range transformed {
 		if value, ok := metric[ambient]; ok {
-			t.gaugeVec.With(prometheus.Labels{
-				arch:                metric[arch].(string),
-				core:                metric[core].(string),
-				device:              metric[device].(string),
-				label:               ambient,
-				uuid:                metric[uuid].(string),
-				bdf:                 metric[bdf].(string),
-				firmwareVersion:     metric[firmwareVersion].(string),
-				pertVersion:         metric[pertVersion].(string),
-				driverVersion:       metric[driverVersion].(string),
-				hostname:            metric[hostname].(string),
-				kubernetesNamespace: metric[kubernetesNamespace].(string),
-				kubernetesPod:       metric[kubernetesPod].(string),
-				kubernetesContainer: metric[kubernetesContainer].(string),
-			}).Set(value.(float64))
+			t.gaugeVec.With(metricLabels(labelNames, metric, prometheus.Labels{label: ambient})).Set(value.(float64))
 		}
 
 		if value, ok := metric[peak]; ok {
-			t.gaugeVec.With(prometheus.Labels{
-				arch:                metric[arch].(string),
-				core:                metric[core].(string),
-				device:              metric[device].(string),
-				label:               peak,
-				uuid:                metric[uuid].(string),
-				bdf:                 metric[bdf].(string),
-				firmwareVersion:     metric[firmwareVersion].(string),
-				pertVersion:         metric[pertVersion].(string),
-				driverVersion:       metric[driverVersion].(string),
-				hostname:            metric[hostname].(string),
-				kubernetesNamespace: metric[kubernetesNamespace].(string),
-				kubernetesPod:       metric[kubernetesPod].(string),
-				kubernetesContainer: metric[kubernetesContainer].(string),
-			}).Set(value.(float64))
+			t.gaugeVec.With(metricLabels(labelNames, metric, prometheus.Labels{label: peak})).Set(value.(float64))
+		}
+
+		if value, ok := metric[thermalHeadroom]; ok {
+			t.thermalHeadroomGaugeVec.With(metricLabels(labelNames, metric, nil)).Set(value.(float64))
+		}
+
+		if value, ok := metric[throttled]; ok {
+			var isThrottled float64
+			if value.(bool) {
+				isThrottled = 1
+			} else {
+				isThrottled = 0
+			}
+
+			t.throttledGaugeVec.With(metricLabels(labelNames, metric, nil)).Set(isThrottled)
 		}
 	}
 
 	return nil
 }
+
+// isThrottled reports whether the device is likely throttled, which is the case when the peak temperature is within
+// the throttle margin of the thermal limit and any core runs under the nominal frequency.
+func isThrottled(thermalHeadroom float64, coreFrequency smi.CoreFrequency, nominalFrequency uint32) bool {
+	if thermalHeadroom > throttleMarginCelsius {
+		return false
+	}
+
+	for _, pe := range coreFrequency.PeFrequency() {
+		if pe.Frequency() < nominalFrequency {
+			return true
+		}
+	}
+
+	return false
+}
@@ -19,6 +19,7 @@ func newFakeTempCollector() Collector {
 
 func TestTempCollector_PostProcessing(t *testing.T) {
 	collector := newFakeTempCollector()
+	useTestRegistry(t)
 	collector.Register()
 
 	tc := MetricContainer{}
@@ -29,6 +30,8 @@ func TestTempCollector_PostProcessing(t *testing.T) {
 	metric[uuid] = uuid
 	metric[ambient] = float64(35)
 	metric[peak] = float64(39)
+	metric[thermalHeadroom] = float64(46)
+	metric[throttled] = false
 	tc = append(tc, metric)
 	err := collector.postProcess(tc)
 	assert.NoError(t, err)
@@ -38,9 +41,23 @@ func TestTempCollector_PostProcessing(t *testing.T) {
 # TYPE furiosa_npu_hw_temperature gauge
 furiosa_npu_hw_temperature{arch="rngd",core="0-7",device="npu0",label="ambient",uuid="uuid"} 35
 furiosa_npu_hw_temperature{arch="rngd",core="0-7",device="npu0",label="peak",uuid="uuid"} 39
+# HELP furiosa_npu_thermal_headroom_celsius The difference between the thermal limit and the current peak temperature of NPU device (Celsius)
+# TYPE furiosa_npu_thermal_headroom_celsius gauge
+furiosa_npu_thermal_headroom_celsius{arch="rngd",core="0-7",device="npu0",uuid="uuid"} 46
+# HELP furiosa_npu_throttled Whether NPU device is likely throttled, i.e. its peak temperature is close to the thermal limit while a core runs under the nominal frequency
+# TYPE furiosa_npu_throttled gauge
+furiosa_npu_throttled{arch="rngd",core="0-7",device="npu0",uuid="uuid"} 0
 `
-	err = testutil.GatherAndCompare(prometheus.DefaultGatherer, strings.NewReader(expected), "furiosa_npu_hw_temperature")
+	err = testutil.GatherAndCompare(prometheus.DefaultGatherer, strings.NewReader(expected), "furiosa_npu_hw_temperature", "furiosa_npu_thermal_headroom_celsius", "furiosa_npu_throttled")
 	assert.NoError(t, err)
+
+	// the series of the devices that are gone are dropped
+	err = collector.postProcess(MetricContainer{})
+	assert.NoError(t, err)
+
+	temperatureCollector := collector.(*temperatureCollector)
+	assert.Equal(t, 0, testutil.CollectAndCount(temperatureCollector.thermalHeadroomGaugeVec))
+	assert.Equal(t, 0, testutil.CollectAndCount(temperatureCollector.throttledGaugeVec))
 }
 
 func TestTempCollector_Collect(t *testing.T) {
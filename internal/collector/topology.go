@@ -0,0 +1,259 @@
+package collector
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/furiosa-ai/furiosa-smi-go/pkg/smi"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	srcUUID       = "src_uuid"
+	dstUUID       = "dst_uuid"
+	linkType      = "link_type"
+	p2pAccessible = "p2pAccessible"
+)
+
+// TopologyCollector collects the device-to-device topology, and serves the topology matrix as JSON.
+type TopologyCollector interface {
+	Collector
+	http.Handler
+}
+
+type topologyDevice struct {
+	Device string `json:"device"`
+	UUID   string `json:"uuid"`
+	BDF    string `json:"pci_bus_id"`
+}
+
+type topologyLink struct {
+	SrcUUID  string `json:"src_uuid"`
+	DstUUID  string `json:"dst_uuid"`
+	LinkType string `json:"link_type"`
+	// P2PAccessible is nil if the p2p accessibility of the pair cannot be read.
+	P2PAccessible *bool `json:"p2p_accessible"`
+}
+
+type topologyMatrix struct {
+	Devices []topologyDevice `json:"devices"`
+	Links   []topologyLink   `json:"links"`
+}
+
+// topologyCollector exports the device-to-device topology, which is static for the lifetime of the exporter and thus
+// built on registration. The matrix is rebuilt on every collection until all the devices and pairs can be read, so
+// that the devices which become readable later are added.
+type topologyCollector struct {
+	devices []smi.Device
+
+	linkInfoGaugeVec      *prometheus.GaugeVec
+	p2pAccessibleGaugeVec *prometheus.GaugeVec
+
+	sync.RWMutex
+	matrix *topologyMatrix
+	// complete is set once the matrix is built without leaving out any device or pair.
+	complete bool
+}
+
+var _ TopologyCollector = (*topologyCollector)(nil)
+
+func NewTopologyCollector(devices []smi.Device) TopologyCollector {
+	return &topologyCollector{
+		devices: devices,
+	}
+}
+
+func (t *topologyCollector) Register() {
+	linkInfoOpts := prometheus.GaugeOpts{
+		Name: "furiosa_npu_link_info",
+		Help: "The link type between two NPU devices. The value is always 1",
+	}
+
+	t.linkInfoGaugeVec = prometheus.NewGaugeVec(linkInfoOpts, []string{srcUUID, dstUUID, linkType})
+
+	prometheus.MustRegister(NewLabelFilterCollector(
+		t.linkInfoGaugeVec,
+		prometheus.Opts(linkInfoOpts),
+		prometheus.GaugeValue,
+	))
+
+	p2pAccessibleOpts := prometheus.GaugeOpts{
+		Name: "furiosa_npu_p2p_accessible",
+		Help: "Whether two NPU devices are p2p accessible each other",
+	}
+
+	t.p2pAccessibleGaugeVec = prometheus.NewGaugeVec(p2pAccessibleOpts, []string{srcUUID, dstUUID})
+
+	prometheus.MustRegister(NewLabelFilterCollector(
+		t.p2pAccessibleGaugeVec,
+		prometheus.Opts(p2pAccessibleOpts),
+		prometheus.GaugeValue,
+	))
+
+	// the errors are returned by the first collection, which builds the matrix again
+	_ = t.build()
+}
+
+// build rebuilds the matrix unless it is complete, and returns the errors of the devices and pairs left out.
+func (t *topologyCollector) build() error {
+	t.RLock()
+	complete := t.complete
+	t.RUnlock()
+	if complete {
+		return nil
+	}
+
+	matrix, err := buildTopologyMatrix(t.devices)
+
+	t.Lock()
+	defer t.Unlock()
+	t.matrix = matrix
+	t.complete = err == nil
+
+	return err
+}
+
+func (t *topologyCollector) Collect() error {
+	buildErr := t.build()
+
+	t.RLock()
+	matrix := t.matrix
+	t.RUnlock()
+
+	metricContainer := make(MetricContainer, 0, len(matrix.Links))
+	for _, link := range matrix.Links {
+		metric := Metric{
+			srcUUID:  link.SrcUUID,
+			dstUUID:  link.DstUUID,
+			linkType: link.LinkType,
+		}
+		if link.P2PAccessible != nil {
+			metric[p2pAccessible] = *link.P2PAccessible
+		}
+		metricContainer = append(metricContainer, metric)
+	}
+
+	if err := t.postProcess(metricContainer); err != nil {
+		return err
+	}
+
+	return buildErr
+}
+
+func (t *topologyCollector) postProcess(metrics MetricContainer) error {
+	t.linkInfoGaugeVec.Reset()
+	t.p2pAccessibleGaugeVec.Reset()
+
+	for _, metric := range metrics {
+		t.linkInfoGaugeVec.With(prometheus.Labels{
+			srcUUID:  metric[srcUUID].(string),
+			dstUUID:  metric[dstUUID].(string),
+			linkType: metric[linkType].(string),
+		}).Set(1)
+
+		if value, ok := metric[p2pAccessible]; ok {
+			var accessible float64
+			if value.(bool) {
+				accessible = 1
+			} else {
+				accessible = 0
+			}
+
+			t.p2pAccessibleGaugeVec.With(prometheus.Labels{
+				srcUUID: metric[srcUUID].(string),
+				dstUUID: metric[dstUUID].(string),
+			}).Set(accessible)
+		}
+	}
+
+	return nil
+}
+
+func (t *topologyCollector) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	t.RLock()
+	defer t.RUnlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(t.matrix)
+}
+
+// buildTopologyMatrix returns the links between every pair of devices. The devices whose information cannot be read
+// are left out, the pairs whose link type cannot be read are skipped, and the pairs whose p2p accessibility cannot be
+// read have no accessibility. The errors of the parts left out are returned along with the matrix.
+func buildTopologyMatrix(devices []smi.Device) (*topologyMatrix, error) {
+	errs := make([]error, 0)
+
+	readable := make([]smi.Device, 0, len(devices))
+	topologyDevices := make([]topologyDevice, 0, len(devices))
+	for _, d := range devices {
+		info, err := d.DeviceInfo()
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		readable = append(readable, d)
+		topologyDevices = append(topologyDevices, topologyDevice{
+			Device: info.Name(),
+			UUID:   info.UUID(),
+			BDF:    info.BDF(),
+		})
+	}
+
+	matrix := &topologyMatrix{
+		Devices: topologyDevices,
+		Links:   make([]topologyLink, 0, len(readable)*len(readable)),
+	}
+
+	for i, src := range readable {
+		for j, dst := range readable {
+			if i == j {
+				continue
+			}
+
+			lt, err := src.DeviceToDeviceLinkType(dst)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to get link type between '%s' and '%s'; err: %w", topologyDevices[i].UUID, topologyDevices[j].UUID, err))
+				continue
+			}
+
+			link := topologyLink{
+				SrcUUID:  topologyDevices[i].UUID,
+				DstUUID:  topologyDevices[j].UUID,
+				LinkType: linkTypeToString(lt),
+			}
+
+			if accessible, err := src.P2PAccessible(dst); err != nil {
+				errs = append(errs, fmt.Errorf("failed to get p2p accessibility between '%s' and '%s'; err: %w", topologyDevices[i].UUID, topologyDevices[j].UUID, err))
+			} else {
+				link.P2PAccessible = &accessible
+			}
+
+			matrix.Links = append(matrix.Links, link)
+		}
+	}
+
+	if len(errs) > 0 {
+		return matrix, errors.Join(errs...)
+	}
+
+	return matrix, nil
+}
+
+func linkTypeToString(lt smi.LinkType) string {
+	switch lt {
+	case smi.LinkTypeInterconnect:
+		return "interconnect"
+	case smi.LinkTypeCpu:
+		return "cpu"
+	case smi.LinkTypeHostBridge:
+		return "host_bridge"
+	case smi.LinkTypeNoc:
+		return "noc"
+	default:
+		return "unknown"
+	}
+}
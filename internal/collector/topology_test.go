@@ -0,0 +1,114 @@
+package collector
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/furiosa-ai/furiosa-smi-go/pkg/smi"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestTopologyCollector_Collect(t *testing.T) {
+	devices := []smi.Device{
+		smi.GetStaticMockDevice(smi.ArchRngd, 0),
+		smi.GetStaticMockDevice(smi.ArchRngd, 1),
+		smi.GetStaticMockDevice(smi.ArchRngd, 4),
+	}
+
+	collector := NewTopologyCollector(devices)
+	useTestRegistry(t)
+	collector.Register()
+
+	err := collector.Collect()
+	assert.NoError(t, err)
+
+	expected := `
+# HELP furiosa_npu_link_info The link type between two NPU devices. The value is always 1
+# TYPE furiosa_npu_link_info gauge
+furiosa_npu_link_info{dst_uuid="A76AAD68-6855-40B1-9E86-D080852D1C80",link_type="host_bridge",src_uuid="A76AAD68-6855-40B1-9E86-D080852D1C81"} 1
+furiosa_npu_link_info{dst_uuid="A76AAD68-6855-40B1-9E86-D080852D1C80",link_type="interconnect",src_uuid="A76AAD68-6855-40B1-9E86-D080852D1C84"} 1
+furiosa_npu_link_info{dst_uuid="A76AAD68-6855-40B1-9E86-D080852D1C81",link_type="host_bridge",src_uuid="A76AAD68-6855-40B1-9E86-D080852D1C80"} 1
+furiosa_npu_link_info{dst_uuid="A76AAD68-6855-40B1-9E86-D080852D1C81",link_type="interconnect",src_uuid="A76AAD68-6855-40B1-9E86-D080852D1C84"} 1
+furiosa_npu_link_info{dst_uuid="A76AAD68-6855-40B1-9E86-D080852D1C84",link_type="interconnect",src_uuid="A76AAD68-6855-40B1-9E86-D080852D1C80"} 1
+furiosa_npu_link_info{dst_uuid="A76AAD68-6855-40B1-9E86-D080852D1C84",link_type="interconnect",src_uuid="A76AAD68-6855-40B1-9E86-D080852D1C81"} 1
+# HELP furiosa_npu_p2p_accessible Whether two NPU devices are p2p accessible each other
+# TYPE furiosa_npu_p2p_accessible gauge
+furiosa_npu_p2p_accessible{dst_uuid="A76AAD68-6855-40B1-9E86-D080852D1C80",src_uuid="A76AAD68-6855-40B1-9E86-D080852D1C81"} 1
+furiosa_npu_p2p_accessible{dst_uuid="A76AAD68-6855-40B1-9E86-D080852D1C80",src_uuid="A76AAD68-6855-40B1-9E86-D080852D1C84"} 1
+furiosa_npu_p2p_accessible{dst_uuid="A76AAD68-6855-40B1-9E86-D080852D1C81",src_uuid="A76AAD68-6855-40B1-9E86-D080852D1C80"} 1
+furiosa_npu_p2p_accessible{dst_uuid="A76AAD68-6855-40B1-9E86-D080852D1C81",src_uuid="A76AAD68-6855-40B1-9E86-D080852D1C84"} 1
+furiosa_npu_p2p_accessible{dst_uuid="A76AAD68-6855-40B1-9E86-D080852D1C84",src_uuid="A76AAD68-6855-40B1-9E86-D080852D1C80"} 1
+furiosa_npu_p2p_accessible{dst_uuid="A76AAD68-6855-40B1-9E86-D080852D1C84",src_uuid="A76AAD68-6855-40B1-9E86-D080852D1C81"} 1
+`
+	err = testutil.GatherAndCompare(prometheus.DefaultGatherer, strings.NewReader(expected), "furiosa_npu_link_info", "furiosa_npu_p2p_accessible")
+	assert.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	collector.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/topology", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	matrix := topologyMatrix{}
+	err = json.Unmarshal(recorder.Body.Bytes(), &matrix)
+	assert.NoError(t, err)
+	assert.Len(t, matrix.Devices, 3)
+	assert.Len(t, matrix.Links, 6)
+	accessible := true
+	assert.Equal(t, topologyLink{
+		SrcUUID:       "A76AAD68-6855-40B1-9E86-D080852D1C80",
+		DstUUID:       "A76AAD68-6855-40B1-9E86-D080852D1C81",
+		LinkType:      "host_bridge",
+		P2PAccessible: &accessible,
+	}, matrix.Links[0])
+}
+
+// unreadableDevice is a device whose information cannot be read.
+type unreadableDevice struct {
+	smi.Device
+}
+
+func (d unreadableDevice) DeviceInfo() (smi.DeviceInfo, error) {
+	return nil, errors.New("device is not readable")
+}
+
+func TestBuildTopologyMatrix_UnreadableDevice(t *testing.T) {
+	devices := []smi.Device{
+		smi.GetStaticMockDevice(smi.ArchRngd, 0),
+		unreadableDevice{smi.GetStaticMockDevice(smi.ArchRngd, 1)},
+		smi.GetStaticMockDevice(smi.ArchRngd, 4),
+	}
+
+	// the unreadable device is left out, while the links between the other devices are kept
+	matrix, err := buildTopologyMatrix(devices)
+	assert.Error(t, err)
+	assert.Len(t, matrix.Devices, 2)
+	assert.Len(t, matrix.Links, 2)
+	assert.Equal(t, "A76AAD68-6855-40B1-9E86-D080852D1C84", matrix.Links[0].DstUUID)
+}
+
+func TestTopologyCollector_RecoveredDevice(t *testing.T) {
+	devices := []smi.Device{
+		smi.GetStaticMockDevice(smi.ArchRngd, 0),
+		smi.GetStaticMockDevice(smi.ArchRngd, 1),
+		smi.GetStaticMockDevice(smi.ArchRngd, 4),
+	}
+
+	collector := NewTopologyCollector([]smi.Device{devices[0], unreadableDevice{devices[1]}, devices[2]}).(*topologyCollector)
+	useTestRegistry(t)
+	collector.Register()
+
+	assert.Error(t, collector.Collect())
+	assert.Equal(t, 2, testutil.CollectAndCount(collector.linkInfoGaugeVec))
+
+	// the recovered device is added, and the complete matrix reports no error
+	collector.devices = devices
+	assert.NoError(t, collector.Collect())
+	assert.Equal(t, 6, testutil.CollectAndCount(collector.linkInfoGaugeVec))
+	assert.NoError(t, collector.Collect())
+}
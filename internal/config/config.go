@@ -1,12 +1,28 @@
 package config
 
 import (
+	"errors"
 	"os"
 )
 
 const (
-	defaultPort     = 6254
-	defaultInterval = 10
+	defaultPort      = 6254
+	defaultInterval  = 10
+	defaultSysfsRoot = "/sys"
+	defaultProcRoot  = "/proc"
+
+	defaultKubeletSocket = "/var/lib/kubelet/pod-resources/kubelet.sock"
+	defaultKubeCacheTTL  = 60
+
+	defaultKubeletCheckpoint = "/var/lib/kubelet/device-plugins/kubelet_internal_checkpoint"
+
+	defaultAttributionMode = "duplicate"
+
+	defaultLivenessFlappingThreshold = 3
+	defaultLivenessFlappingWindow    = 300
+	defaultLivenessFastInterval      = 1
+
+	defaultIdleUtilizationThreshold = 1.0
 )
 
 type Config struct {
@@ -14,6 +30,51 @@ type Config struct {
 	Interval           int    `yaml:"interval"`
 	NodeName           string `yaml:"nodeName"`
 	KubeResourcesLabel bool   `yaml:"kubeResourcesLabel"`
+	KubeletSocket      string `yaml:"kubeletSocket"`
+	// KubeCacheTTL is how long (seconds) the last pod information is kept while the kubelet cannot be reached.
+	KubeCacheTTL     int    `yaml:"kubeCacheTTL"`
+	CgroupFallback   bool   `yaml:"cgroupFallback"`
+	SysfsRoot        string `yaml:"sysfsRoot"`
+	InfoMetricLabels bool   `yaml:"infoMetricLabels"`
+	ProcessLabel     bool   `yaml:"processLabel"`
+	ProcRoot         string `yaml:"procRoot"`
+
+	// CheckpointFallback enables attributing the devices from the kubelet device manager checkpoint KubeletCheckpoint
+	// while the kubelet pod resources API is unavailable.
+	CheckpointFallback bool   `yaml:"checkpointFallback"`
+	KubeletCheckpoint  string `yaml:"kubeletCheckpoint"`
+
+	// PodInformer enables watching the pods of the node through the API server, using the in-cluster config or
+	// KubeConfig, to add the allowlisted pod labels and annotations to the attributed metrics.
+	PodInformer             bool     `yaml:"podInformer"`
+	KubeConfig              string   `yaml:"kubeConfig"`
+	PodLabelsAllowlist      []string `yaml:"podLabelsAllowlist"`
+	PodAnnotationsAllowlist []string `yaml:"podAnnotationsAllowlist"`
+	PodOwnerLabels          bool     `yaml:"podOwnerLabels"`
+
+	// AttributionMode decides how the device-wise metrics of the devices allocated to pods are attributed, one of
+	// duplicate, replace, split and info.
+	AttributionMode string `yaml:"attributionMode"`
+
+	// LivenessFlappingThreshold is the number of liveness changes within LivenessFlappingWindow (seconds) above which a
+	// device is flapping. LivenessFastInterval (seconds) is the liveness sampling interval while a device is recently
+	// unhealthy, 0 disables it.
+	LivenessFlappingThreshold int `yaml:"livenessFlappingThreshold"`
+	LivenessFlappingWindow    int `yaml:"livenessFlappingWindow"`
+	LivenessFastInterval      int `yaml:"livenessFastInterval"`
+
+	// IdleUtilizationThreshold is the core utilization (percent) under which an allocated core is considered idle.
+	IdleUtilizationThreshold float64 `yaml:"idleUtilizationThreshold"`
+	// PodAggregateMetrics enables the metrics aggregated by pod and namespace, splitting the device values by the
+	// fraction of the allocated cores.
+	PodAggregateMetrics bool `yaml:"podAggregateMetrics"`
+
+	// ThermalLimits and PowerLimits override the default limits of each arch (e.g. "rngd") used to derive headroom metrics.
+	ThermalLimits map[string]float64 `yaml:"thermalLimits"`
+	PowerLimits   map[string]float64 `yaml:"powerLimits"`
+	// NominalCoreFrequencies override the default core frequency (MHz) of each arch below which a device close to its
+	// thermal limit is regarded as throttled.
+	NominalCoreFrequencies map[string]float64 `yaml:"nominalCoreFrequencies"`
 }
 
 func (c *Config) SetPort(port int) {
@@ -32,6 +93,98 @@ func (c *Config) SetKubeResourcesLabel(kubeResourcesLabel bool) {
 	c.KubeResourcesLabel = kubeResourcesLabel
 }
 
+func (c *Config) SetKubeletSocket(kubeletSocket string) {
+	c.KubeletSocket = kubeletSocket
+}
+
+func (c *Config) SetKubeCacheTTL(kubeCacheTTL int) {
+	c.KubeCacheTTL = kubeCacheTTL
+}
+
+func (c *Config) SetCgroupFallback(cgroupFallback bool) {
+	c.CgroupFallback = cgroupFallback
+}
+
+func (c *Config) SetCheckpointFallback(checkpointFallback bool) {
+	c.CheckpointFallback = checkpointFallback
+}
+
+func (c *Config) SetKubeletCheckpoint(kubeletCheckpoint string) {
+	c.KubeletCheckpoint = kubeletCheckpoint
+}
+
+func (c *Config) SetSysfsRoot(sysfsRoot string) {
+	c.SysfsRoot = sysfsRoot
+}
+
+func (c *Config) SetInfoMetricLabels(infoMetricLabels bool) {
+	c.InfoMetricLabels = infoMetricLabels
+}
+
+func (c *Config) SetProcessLabel(processLabel bool) {
+	c.ProcessLabel = processLabel
+}
+
+func (c *Config) SetProcRoot(procRoot string) {
+	c.ProcRoot = procRoot
+}
+
+func (c *Config) SetPodInformer(podInformer bool) {
+	c.PodInformer = podInformer
+}
+
+func (c *Config) SetKubeConfig(kubeConfig string) {
+	c.KubeConfig = kubeConfig
+}
+
+func (c *Config) SetPodLabelsAllowlist(podLabelsAllowlist []string) {
+	c.PodLabelsAllowlist = podLabelsAllowlist
+}
+
+func (c *Config) SetPodAnnotationsAllowlist(podAnnotationsAllowlist []string) {
+	c.PodAnnotationsAllowlist = podAnnotationsAllowlist
+}
+
+func (c *Config) SetPodOwnerLabels(podOwnerLabels bool) {
+	c.PodOwnerLabels = podOwnerLabels
+}
+
+func (c *Config) SetAttributionMode(attributionMode string) {
+	c.AttributionMode = attributionMode
+}
+
+func (c *Config) SetLivenessFlappingThreshold(livenessFlappingThreshold int) {
+	c.LivenessFlappingThreshold = livenessFlappingThreshold
+}
+
+func (c *Config) SetLivenessFlappingWindow(livenessFlappingWindow int) {
+	c.LivenessFlappingWindow = livenessFlappingWindow
+}
+
+func (c *Config) SetLivenessFastInterval(livenessFastInterval int) {
+	c.LivenessFastInterval = livenessFastInterval
+}
+
+func (c *Config) SetIdleUtilizationThreshold(idleUtilizationThreshold float64) {
+	c.IdleUtilizationThreshold = idleUtilizationThreshold
+}
+
+func (c *Config) SetPodAggregateMetrics(podAggregateMetrics bool) {
+	c.PodAggregateMetrics = podAggregateMetrics
+}
+
+func (c *Config) SetThermalLimits(thermalLimits map[string]float64) {
+	c.ThermalLimits = thermalLimits
+}
+
+func (c *Config) SetPowerLimits(powerLimits map[string]float64) {
+	c.PowerLimits = powerLimits
+}
+
+func (c *Config) SetNominalCoreFrequencies(nominalCoreFrequencies map[string]float64) {
+	c.NominalCoreFrequencies = nominalCoreFrequencies
+}
+
 func NewDefaultConfig() *Config {
 	return &Config{
 		Port:     defaultPort,
@@ -40,5 +193,66 @@ func NewDefaultConfig() *Config {
 		// Set NodeName from `NODE_NAME` env. If not set, leave it empty.
 		NodeName:           os.Getenv("NODE_NAME"),
 		KubeResourcesLabel: false,
+		KubeletSocket:      defaultKubeletSocket,
+		KubeCacheTTL:       defaultKubeCacheTTL,
+		CgroupFallback:     false,
+		SysfsRoot:          defaultSysfsRoot,
+		InfoMetricLabels:   false,
+		ProcessLabel:       false,
+		ProcRoot:           defaultProcRoot,
+
+		CheckpointFallback: false,
+		KubeletCheckpoint:  defaultKubeletCheckpoint,
+
+		AttributionMode: defaultAttributionMode,
+
+		LivenessFlappingThreshold: defaultLivenessFlappingThreshold,
+		LivenessFlappingWindow:    defaultLivenessFlappingWindow,
+		LivenessFastInterval:      defaultLivenessFastInterval,
+
+		IdleUtilizationThreshold: defaultIdleUtilizationThreshold,
+		PodAggregateMetrics:      false,
 	}
 }
+
+// Validate rejects the combinations of options that would be silently ignored.
+func (c *Config) Validate() error {
+	errs := make([]error, 0)
+	if c.ProcessLabel && c.KubeResourcesLabel {
+		errs = append(errs, errors.New("--process-label cannot be combined with --kube-resources-label"))
+	}
+
+	if c.AttributionMode != "" && c.AttributionMode != defaultAttributionMode && !c.KubeResourcesLabel {
+		errs = append(errs, errors.New("--attribution-mode other than duplicate requires --kube-resources-label"))
+	}
+
+	if c.CgroupFallback && !c.KubeResourcesLabel {
+		errs = append(errs, errors.New("--cgroup-fallback requires --kube-resources-label"))
+	}
+
+	if c.CheckpointFallback && !c.KubeResourcesLabel {
+		errs = append(errs, errors.New("--checkpoint-fallback requires --kube-resources-label"))
+	}
+
+	if c.PodAggregateMetrics && !c.KubeResourcesLabel {
+		errs = append(errs, errors.New("--pod-aggregate-metrics requires --kube-resources-label"))
+	}
+
+	if c.PodInformer && !c.KubeResourcesLabel {
+		errs = append(errs, errors.New("--pod-informer requires --kube-resources-label"))
+	}
+
+	if len(c.PodLabelsAllowlist) > 0 && !c.PodInformer {
+		errs = append(errs, errors.New("--pod-labels-allowlist requires --pod-informer"))
+	}
+
+	if len(c.PodAnnotationsAllowlist) > 0 && !c.PodInformer {
+		errs = append(errs, errors.New("--pod-annotations-allowlist requires --pod-informer"))
+	}
+
+	if c.PodOwnerLabels && !c.PodInformer {
+		errs = append(errs, errors.New("--pod-owner-labels requires --pod-informer"))
+	}
+
+	return errors.Join(errs...)
+}
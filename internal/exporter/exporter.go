@@ -24,12 +24,48 @@ type Exporter struct {
 }
 
 func NewGenericExporter(ctx context.Context, logger zerolog.Logger, cfg *config.Config, devices []smi.Device, metricFactory collector.MetricFactory, errChan chan error) (*Exporter, error) {
-	kubeResMapper, kubeResSyncChan, err := collector.NewKubeResourcesMapper(ctx, cfg.KubeResourcesLabel)
+	var kubeResMapper collector.KubeResourcesMapper
+	var kubeResSyncChan chan<- struct{}
+	var err error
+
+	attributionMode, err := collector.ParseAttributionMode(cfg.AttributionMode)
 	if err != nil {
 		return nil, err
 	}
 
-	newDefaultPipeline := pipeline.NewRegisteredPipeline(devices, metricFactory, kubeResMapper)
+	if cfg.ProcessLabel {
+		// on bare-metal hosts, attribute the metrics to the processes holding the device files instead of pods
+		kubeResMapper, kubeResSyncChan, err = collector.NewProcessResourcesMapper(ctx, logger, cfg.ProcRoot, devices)
+	} else {
+		kubeResMapper, kubeResSyncChan, err = collector.NewKubeResourcesMapper(ctx, logger, collector.KubeResourcesMapperOptions{
+			Enabled:            cfg.KubeResourcesLabel,
+			KubeletSocket:      cfg.KubeletSocket,
+			CacheTTL:           time.Duration(cfg.KubeCacheTTL) * time.Second,
+			CgroupFallback:     cfg.CgroupFallback,
+			ProcRoot:           cfg.ProcRoot,
+			CheckpointFallback: cfg.CheckpointFallback,
+			KubeletCheckpoint:  cfg.KubeletCheckpoint,
+			PodMetadata: collector.PodMetadataOptions{
+				Enabled:              cfg.PodInformer,
+				NodeName:             cfg.NodeName,
+				KubeConfig:           cfg.KubeConfig,
+				LabelsAllowlist:      cfg.PodLabelsAllowlist,
+				AnnotationsAllowlist: cfg.PodAnnotationsAllowlist,
+				OwnerLabels:          cfg.PodOwnerLabels,
+			},
+			AttributionMode: attributionMode,
+		}, devices)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	limits, err := collector.NewDeviceLimits(cfg.ThermalLimits, cfg.PowerLimits, cfg.NominalCoreFrequencies)
+	if err != nil {
+		return nil, err
+	}
+
+	newDefaultPipeline := pipeline.NewRegisteredPipeline(cfg, devices, metricFactory, kubeResMapper, limits)
 
 	exporter := Exporter{
 		logger:          logger,
@@ -40,6 +76,7 @@ func NewGenericExporter(ctx context.Context, logger zerolog.Logger, cfg *config.
 				// build Webserver
 				mux := http.NewServeMux()
 				mux.Handle("/metrics", promhttp.Handler())
+				mux.Handle("/topology", newDefaultPipeline.TopologyHandler())
 
 				return mux
 			}(),
@@ -53,6 +90,9 @@ func NewGenericExporter(ctx context.Context, logger zerolog.Logger, cfg *config.
 }
 
 func (e *Exporter) Start(ctx context.Context) {
+	//start background sampling between collections
+	e.pipeline.StartSampling(ctx)
+
 	//run pipeline
 	go func() {
 		tick := time.NewTicker(time.Second * time.Duration(e.collectInterval))
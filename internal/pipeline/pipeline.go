@@ -1,26 +1,67 @@
 package pipeline
 
 import (
+	"context"
+	"net/http"
 	"sync"
+	"time"
 
 	"github.com/furiosa-ai/furiosa-metrics-exporter/internal/collector"
+	"github.com/furiosa-ai/furiosa-metrics-exporter/internal/config"
 	"github.com/furiosa-ai/furiosa-smi-go/pkg/smi"
 )
 
 type Pipeline struct {
 	collectors []collector.Collector
+	topology   collector.TopologyCollector
 }
 
-func NewRegisteredPipeline(devices []smi.Device, metricFactory collector.MetricFactory, kubeResMapper collector.KubeResourcesMapper) *Pipeline {
+func NewRegisteredPipeline(cfg *config.Config, devices []smi.Device, metricFactory collector.MetricFactory, kubeResMapper collector.KubeResourcesMapper, limits collector.DeviceLimits) *Pipeline {
+	topology := collector.NewTopologyCollector(devices)
+	samples := collector.NewDeviceSamples()
+
 	p := Pipeline{
 		collectors: []collector.Collector{
-			collector.NewTemperatureCollector(devices, metricFactory, kubeResMapper),
-			collector.NewPowerCollector(devices, metricFactory, kubeResMapper),
-			collector.NewLivenessCollector(devices, metricFactory, kubeResMapper),
-			collector.NewCoreUtilizationCollector(devices, metricFactory, kubeResMapper),
+			collector.NewTemperatureCollector(devices, metricFactory, kubeResMapper, limits),
+			collector.NewPowerCollector(devices, metricFactory, kubeResMapper, limits, samples),
+			collector.NewLivenessCollector(devices, metricFactory, kubeResMapper, collector.LivenessOptions{
+				FlappingThreshold: cfg.LivenessFlappingThreshold,
+				FlappingWindow:    time.Duration(cfg.LivenessFlappingWindow) * time.Second,
+				FastInterval:      time.Duration(cfg.LivenessFastInterval) * time.Second,
+			}),
+			collector.NewCoreUtilizationCollector(devices, metricFactory, kubeResMapper, samples),
 			collector.NewCoreFrequencyCollector(devices, metricFactory, kubeResMapper),
+			collector.NewMemoryFrequencyCollector(devices, metricFactory, kubeResMapper),
 			collector.NewCycleCollector(devices, metricFactory, kubeResMapper),
+			collector.NewCoreStatusCollector(devices, metricFactory, kubeResMapper),
+			collector.NewGovernorProfileCollector(devices, metricFactory, kubeResMapper),
+			collector.NewDeviceInfoCollector(devices, metricFactory, kubeResMapper),
+			collector.NewErrorCollector(devices, metricFactory, kubeResMapper, cfg.SysfsRoot),
+			collector.NewPCIeCollector(devices, metricFactory, kubeResMapper, cfg.SysfsRoot),
+			topology,
 		},
+		topology: topology,
+	}
+
+	if cfg.InfoMetricLabels {
+		p.collectors = append(p.collectors, collector.NewInfoCollector(devices, metricFactory))
+	}
+
+	if allocationMapper, ok := kubeResMapper.(collector.AllocationStateMapper); ok && cfg.KubeResourcesLabel {
+		p.collectors = append(p.collectors, collector.NewAllocationCollector(devices, metricFactory, allocationMapper))
+		p.collectors = append(p.collectors, collector.NewAllocationIdleCollector(devices, metricFactory, allocationMapper, cfg.IdleUtilizationThreshold))
+
+		if cfg.AttributionMode == string(collector.AttributionModeInfo) {
+			p.collectors = append(p.collectors, collector.NewAllocationInfoCollector(devices, metricFactory, allocationMapper))
+		}
+
+		if cfg.PodAggregateMetrics {
+			p.collectors = append(p.collectors, collector.NewPodAggregateCollector(devices, metricFactory, allocationMapper, samples))
+		}
+	}
+
+	if processMapper, ok := kubeResMapper.(collector.ProcessResourcesMapper); ok {
+		p.collectors = append(p.collectors, collector.NewProcessInfoCollector(processMapper))
 	}
 
 	for _, c := range p.collectors {
@@ -30,6 +71,20 @@ func NewRegisteredPipeline(devices []smi.Device, metricFactory collector.MetricF
 	return &p
 }
 
+// TopologyHandler returns the handler serving the device-to-device topology matrix as JSON.
+func (p *Pipeline) TopologyHandler() http.Handler {
+	return p.topology
+}
+
+// StartSampling starts the background sampling of the collectors sampling the devices between two collections.
+func (p *Pipeline) StartSampling(ctx context.Context) {
+	for _, c := range p.collectors {
+		if sampler, ok := c.(collector.BackgroundSampler); ok {
+			sampler.StartSampling(ctx)
+		}
+	}
+}
+
 func (p *Pipeline) Collect() []error {
 	errors := make([]error, len(p.collectors))
 